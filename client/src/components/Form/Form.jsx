@@ -79,6 +79,8 @@ const Form = () => {
 			.then(function (response) {
 				console.log(response);
 				localStorage.setItem("_id", response.data.data);
+				// Proves this browser owns the guest session
+				localStorage.setItem("guestToken", response.headers["x-guest-token"] || "");
 				navigate("/camera-checkup");
 			})
 			.catch(function (error) {
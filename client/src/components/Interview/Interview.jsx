@@ -26,6 +26,8 @@ const Interview = () => {
 
     // 2. State Management
     const videoRef = useRef(null);
+    // Number of the question currently being answered (0 before the first)
+    const turnRef = useRef(0);
     const [gettingGeminiResponse, setGettingGeminiResponse] = useState(false);
     const [geminiResponse, setGeminiResponse] = useState("Looking for a response...");
     
@@ -57,28 +59,44 @@ const Interview = () => {
             setGeminiResponse("Thinking...");
 
             let payload = manualAnswer !== null ? manualAnswer : userTranscript;
+            let source = manualAnswer !== null ? "typed" : "speech";
 
             if (hasCodeChanged && code) {
                 payload += `\n\n[CODE_SUBMISSION]\n${code}`;
+                source = "code";
             }
 
             console.log("Sending Payload to Gemini:", payload);
 
             const formData = new FormData();
             formData.append("answer", payload);
-
-            const response = await axios.post(
-                `${SERVER}/api/v1/ask-to-gemini/${sessionId}`,
-                formData,
-                {
-                    headers: { "Content-Type": "multipart/form-data" },
-                }
-            );
+            formData.append("source", source);
+            formData.append("turn", turnRef.current);
+
+            // Reuse the same key if the request is retried so the server
+            // replays the turn instead of answering it twice
+            const requestConfig = {
+                headers: {
+                    "Content-Type": "multipart/form-data",
+                    "Idempotency-Key": crypto.randomUUID(),
+                    "X-Guest-Token": localStorage.getItem("guestToken") || "",
+                },
+            };
+            const url = `${SERVER}/api/v1/ask-to-gemini/${sessionId}`;
+
+            let response;
+            try {
+                response = await axios.post(url, formData, requestConfig);
+            } catch (error) {
+                if (error.response) throw error;
+                response = await axios.post(url, formData, requestConfig);
+            }
 
             console.log("Gemini Response Data:", response.data);
             
             const data = response.data?.data;
             if (data) {
+                turnRef.current = data.turn || turnRef.current;
                 setGeminiResponse(data.question || "No question received.");
                 
                 if (data.code) {
@@ -142,10 +160,13 @@ const Interview = () => {
     const handleEndInterview = async () => {
         try {
             const sessionId = localStorage.getItem("_id");
-            if(sessionId) await axios.post(`${SERVER}/api/v1/end/${sessionId}`);
+            if(sessionId) await axios.post(`${SERVER}/api/v1/end/${sessionId}`, null, {
+                headers: { "X-Guest-Token": localStorage.getItem("guestToken") || "" },
+            });
         } catch (e) { console.error(e); }
 
         localStorage.removeItem("_id");
+        localStorage.removeItem("guestToken");
         navigate("/report", { state: { message: "Session Ended" } });
     };
 
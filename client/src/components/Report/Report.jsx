@@ -61,37 +61,19 @@ const Report = () => {
         pdf.save(`${report.name || localStorage.getItem("_id")}_report.pdf`);
     };
 
-    function parseReviews(reviews) {
-        return reviews.map((review) => {
-            // Extract positive points
-            const positiveMatch = review.match(/<Positive>(.*?)<\/Positive>/);
-            const positivePoints =
-                positiveMatch && positiveMatch[1]
-                    ? [positiveMatch[1].trim()]
-                    : ["No specific positive feedback"];
-
-            // Extract negative points
-            const negativeMatch = review.match(/<Negative>(.*?)<\/Negative>/);
-            const negativePoints =
-                negativeMatch && negativeMatch[1]
-                    ? [negativeMatch[1].trim()]
-                    : ["No specific negative feedback"];
-
-            // Extract improvement points
-            const improvementsMatch = review.match(
-                /<Improvements>(.*?)<\/Improvements>/
-            );
-            const improvementPoints =
-                improvementsMatch && improvementsMatch[1]
-                    ? [improvementsMatch[1].trim()]
-                    : ["No specific improvement suggestions"];
-
-            return {
-                positive: positivePoints,
-                negative: negativePoints,
-                improvements: improvementPoints,
-            };
-        });
+    function parseReviews(turns) {
+        return turns.map(({ feedback }) => ({
+            positive: [
+                feedback?.positive || "No specific positive feedback",
+            ],
+            negative: [
+                feedback?.negative || "No specific negative feedback",
+            ],
+            improvements: [
+                feedback?.improvements ||
+                    "No specific improvement suggestions",
+            ],
+        }));
     }
 
     function formatTime(isoString) {
@@ -122,20 +104,28 @@ const Report = () => {
                 return;
             }
             const response = await axios.post(
-                `${SERVER}/api/v1/end/${sessionId}`
+                `${SERVER}/api/v1/end/${sessionId}`,
+                null,
+                { headers: { "X-Guest-Token": localStorage.getItem("guestToken") || "" } }
             );
             const extractedResponse = response.data?.data;
+            // Only evaluated turns belong in the report
+            const turns = (extractedResponse?.questions?.turns || []).filter(
+                (turn) => turn.rating !== undefined && turn.rating !== null
+            );
 
             const extractedData = {
                 name: extractedResponse?.session?.name || "",
                 experience: extractedResponse?.session?.experience || "",
                 techStacks: extractedResponse?.session?.techStacks || [],
                 projects: extractedResponse?.session?.projects || [],
-                questions: extractedResponse?.questions?.question || [],
-                ratings: extractedResponse?.questions?.rating || [],
-                reviews: parseReviews(
-                    extractedResponse?.questions?.review || []
+                questions: turns.map((turn) =>
+                    turn.code
+                        ? `${turn.question}\n\`\`\`\n${turn.code}\n\`\`\``
+                        : turn.question
                 ),
+                ratings: turns.map((turn) => turn.rating),
+                reviews: parseReviews(turns),
                 startTime: formatTime(extractedResponse?.session?.createdAt),
                 endTime: formatTime(extractedResponse?.session?.updatedAt),
             };
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+)
+
+// unversionedPrompt and unknownModel label ratings stored before the prompt
+// version and grader model were recorded.
+const (
+	unversionedPrompt = "unversioned"
+	unknownModel      = "unknown"
+)
+
+// Agreement compares the AI ratings of reviewed turns with the reviewers'
+// ratings. Kappa and WeightedKappa are nil when every rating fell into the
+// same category, where agreement beyond chance is undefined.
+type Agreement struct {
+	PromptVersion string `json:"promptVersion,omitempty"`
+	Model         string `json:"model,omitempty"`
+	Turns         int    `json:"turns"`
+	// MeanAbsoluteError is the average distance between the two ratings.
+	MeanAbsoluteError float64 `json:"meanAbsoluteError"`
+	// Bias is the average of the AI rating minus the human rating; positive
+	// means the grader is more lenient than reviewers.
+	Bias float64 `json:"bias"`
+	// ExactAgreement is the share of turns rated identically.
+	ExactAgreement float64 `json:"exactAgreement"`
+	// Kappa is Cohen's kappa over the rating categories.
+	Kappa *float64 `json:"kappa"`
+	// WeightedKappa is Cohen's kappa with quadratic weights, which credits
+	// near misses on the ordinal rating scale.
+	WeightedKappa *float64 `json:"weightedKappa"`
+}
+
+// ratingPair is the AI and the human rating of one turn.
+type ratingPair struct {
+	ai, human int
+}
+
+// measureAgreement computes the agreement statistics of pairs.
+func measureAgreement(pairs []ratingPair) Agreement {
+	const categories = models.MaxRating - models.MinRating + 1
+
+	agreement := Agreement{Turns: len(pairs)}
+	if len(pairs) == 0 {
+		return agreement
+	}
+
+	var (
+		observed      [categories][categories]float64
+		aiMarginal    [categories]float64
+		humanMarginal [categories]float64
+		absErr, bias  float64
+		exact         int
+		n             = float64(len(pairs))
+	)
+	for _, pair := range pairs {
+		ai, human := pair.ai-models.MinRating, pair.human-models.MinRating
+		observed[ai][human]++
+		aiMarginal[ai]++
+		humanMarginal[human]++
+
+		diff := float64(pair.ai - pair.human)
+		absErr += math.Abs(diff)
+		bias += diff
+		if pair.ai == pair.human {
+			exact++
+		}
+	}
+
+	agreement.MeanAbsoluteError = roundMetric(absErr / n)
+	agreement.Bias = roundMetric(bias / n)
+	agreement.ExactAgreement = roundMetric(float64(exact) / n)
+
+	// Weighted kappa is 1 - sum(w*observed) / sum(w*expected); unweighted
+	// kappa is the same with w = 0 on the diagonal and 1 elsewhere.
+	var (
+		observedDisagreement, expectedDisagreement                 float64
+		observedWeightedDisagreement, expectedWeightedDisagreement float64
+	)
+	for i := 0; i < categories; i++ {
+		for j := 0; j < categories; j++ {
+			expected := aiMarginal[i] * humanMarginal[j] / n
+			weight := float64((i-j)*(i-j)) / float64((categories-1)*(categories-1))
+			observedWeightedDisagreement += weight * observed[i][j]
+			expectedWeightedDisagreement += weight * expected
+			if i != j {
+				observedDisagreement += observed[i][j]
+				expectedDisagreement += expected
+			}
+		}
+	}
+	if expectedDisagreement > 0 {
+		kappa := roundMetric(1 - observedDisagreement/expectedDisagreement)
+		agreement.Kappa = &kappa
+	}
+	if expectedWeightedDisagreement > 0 {
+		kappa := roundMetric(1 - observedWeightedDisagreement/expectedWeightedDisagreement)
+		agreement.WeightedKappa = &kappa
+	}
+	return agreement
+}
+
+// roundMetric rounds a statistic to three decimal places.
+func roundMetric(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// GetRatingAgreement measures how well the AI grader agrees with reviewers
+// on the turns they overrode, overall and per prompt version and model.
+func GetRatingAgreement(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireReviewer(w, r); !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	turns, err := turnStore.ListReviewedTurns(ctx, requestTenant(r).orgId)
+	if err != nil {
+		log.Println("Failed to fetch reviewed turns:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute agreement")
+		return
+	}
+
+	type groupKey struct{ promptVersion, model string }
+	var all []ratingPair
+	groups := map[groupKey][]ratingPair{}
+	for _, turn := range turns {
+		// Ratings outside the scale predate its validation
+		if turn.Rating == nil || turn.Review == nil || *turn.Rating < models.MinRating || *turn.Rating > models.MaxRating {
+			continue
+		}
+		key := groupKey{turn.PromptVersion, turn.GraderModel}
+		if key.promptVersion == "" {
+			key.promptVersion = unversionedPrompt
+		}
+		if key.model == "" {
+			key.model = unknownModel
+		}
+
+		pair := ratingPair{ai: *turn.Rating, human: turn.Review.Rating}
+		all = append(all, pair)
+		groups[key] = append(groups[key], pair)
+	}
+
+	byGroup := make([]Agreement, 0, len(groups))
+	for key, pairs := range groups {
+		agreement := measureAgreement(pairs)
+		agreement.PromptVersion = key.promptVersion
+		agreement.Model = key.model
+		byGroup = append(byGroup, agreement)
+	}
+	sort.Slice(byGroup, func(i, j int) bool {
+		if byGroup[i].PromptVersion != byGroup[j].PromptVersion {
+			return byGroup[i].PromptVersion < byGroup[j].PromptVersion
+		}
+		return byGroup[i].Model < byGroup[j].Model
+	})
+
+	utils.SuccessResponse(w, "Rating agreement retrieved successfully", map[string]interface{}{
+		"overall": measureAgreement(all),
+		"groups":  byGroup,
+	})
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import "testing"
+
+// repeatPair returns count copies of the pair (ai, human).
+func repeatPair(ai, human, count int) []ratingPair {
+	pairs := make([]ratingPair, count)
+	for i := range pairs {
+		pairs[i] = ratingPair{ai: ai, human: human}
+	}
+	return pairs
+}
+
+func TestMeasureAgreement(t *testing.T) {
+	// The two-rater example of 50 items common in the literature: 20 rated
+	// yes by both, 15 no by both, 5 yes/no and 10 no/yes. Observed agreement
+	// is 0.7 and chance agreement 0.5, so kappa is 0.4. With two categories
+	// every disagreement has the same weight, so the weighted kappa agrees.
+	var textbook []ratingPair
+	textbook = append(textbook, repeatPair(7, 7, 20)...)
+	textbook = append(textbook, repeatPair(7, 3, 5)...)
+	textbook = append(textbook, repeatPair(3, 7, 10)...)
+	textbook = append(textbook, repeatPair(3, 3, 15)...)
+
+	// Disagreements that are all off by one count fully against kappa but
+	// barely against the quadratic-weighted kappa.
+	var nearMisses []ratingPair
+	nearMisses = append(nearMisses, repeatPair(2, 3, 2)...)
+	nearMisses = append(nearMisses, repeatPair(3, 2, 2)...)
+	nearMisses = append(nearMisses, ratingPair{2, 2}, ratingPair{3, 3})
+	nearMisses = append(nearMisses, repeatPair(8, 8, 2)...)
+
+	kappa := func(k float64) *float64 { return &k }
+
+	tests := []struct {
+		name  string
+		pairs []ratingPair
+		want  Agreement
+	}{
+		{
+			name: "no reviewed turns",
+			want: Agreement{},
+		},
+		{
+			name:  "perfect agreement",
+			pairs: []ratingPair{{3, 3}, {5, 5}, {8, 8}, {8, 8}},
+			want:  Agreement{Turns: 4, ExactAgreement: 1, Kappa: kappa(1), WeightedKappa: kappa(1)},
+		},
+		{
+			name:  "every rating in one category",
+			pairs: repeatPair(6, 6, 3),
+			want:  Agreement{Turns: 3, ExactAgreement: 1},
+		},
+		{
+			name:  "textbook two-category table",
+			pairs: textbook,
+			want: Agreement{Turns: 50, MeanAbsoluteError: 1.2, Bias: -0.4, ExactAgreement: 0.7,
+				Kappa: kappa(0.4), WeightedKappa: kappa(0.4)},
+		},
+		{
+			name:  "near misses",
+			pairs: nearMisses,
+			want: Agreement{Turns: 8, MeanAbsoluteError: 0.5, ExactAgreement: 0.5,
+				Kappa: kappa(0.238), WeightedKappa: kappa(0.957)},
+		},
+		{
+			name:  "lenient grader",
+			pairs: []ratingPair{{9, 7}, {8, 6}, {5, 5}, {10, 8}},
+			want: Agreement{Turns: 4, MeanAbsoluteError: 1.5, Bias: 1.5, ExactAgreement: 0.25,
+				Kappa: kappa(0.143), WeightedKappa: kappa(0.571)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := measureAgreement(tt.pairs)
+			if got.Turns != tt.want.Turns || got.MeanAbsoluteError != tt.want.MeanAbsoluteError ||
+				got.Bias != tt.want.Bias || got.ExactAgreement != tt.want.ExactAgreement {
+				t.Errorf("got %d turns, MAE %v, bias %v, exact %v; want %d, %v, %v, %v",
+					got.Turns, got.MeanAbsoluteError, got.Bias, got.ExactAgreement,
+					tt.want.Turns, tt.want.MeanAbsoluteError, tt.want.Bias, tt.want.ExactAgreement)
+			}
+			for _, k := range []struct {
+				name      string
+				got, want *float64
+			}{
+				{"kappa", got.Kappa, tt.want.Kappa},
+				{"weighted kappa", got.WeightedKappa, tt.want.WeightedKappa},
+			} {
+				switch {
+				case k.want == nil && k.got != nil:
+					t.Errorf("%s = %v, want undefined", k.name, *k.got)
+				case k.want != nil && k.got == nil:
+					t.Errorf("%s undefined, want %v", k.name, *k.want)
+				case k.want != nil && *k.got != *k.want:
+					t.Errorf("%s = %v, want %v", k.name, *k.got, *k.want)
+				}
+			}
+		})
+	}
+}
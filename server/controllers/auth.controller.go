@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/auth"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	userStore   store.UserStore
+	tokenIssuer *auth.TokenIssuer
+)
+
+// SetTokenIssuer sets the issuer used to sign and verify access tokens.
+func SetTokenIssuer(issuer *auth.TokenIssuer) {
+	tokenIssuer = issuer
+}
+
+type identityKey struct{}
+
+// identity is the user an access token was issued to.
+type identity struct {
+	userId primitive.ObjectID
+	orgId  primitive.ObjectID
+}
+
+// authenticatedIdentity returns the identity whose token authenticated r.
+func authenticatedIdentity(r *http.Request) (identity, bool) {
+	id, ok := r.Context().Value(identityKey{}).(identity)
+	return id, ok
+}
+
+// authenticatedUser returns the ID of the user whose token authenticated r.
+func authenticatedUser(r *http.Request) (primitive.ObjectID, bool) {
+	id, ok := authenticatedIdentity(r)
+	return id.userId, ok
+}
+
+// Authenticate verifies the bearer token of every request that sends one and
+// records the user it belongs to. Requests without a token continue as
+// guests; requests with a bad token are rejected with 401.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || tokenIssuer == nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid authorization header")
+			return
+		}
+
+		claims, err := tokenIssuer.Verify(strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, auth.ErrTokenExpired) {
+				utils.ErrorResponse(w, http.StatusUnauthorized, "Token has expired")
+				return
+			}
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+
+		var id identity
+		if id.userId, err = primitive.ObjectIDFromHex(claims.Subject); err != nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+		if claims.Org != "" {
+			if id.orgId, err = primitive.ObjectIDFromHex(claims.Org); err != nil {
+				utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid token")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	})
+}
+
+// authResponse is returned by signup and login.
+func authResponse(user *models.UserAccount) (map[string]interface{}, error) {
+	claims := auth.Claims{Subject: user.ID.Hex()}
+	if !user.OrgID.IsZero() {
+		claims.Org = user.OrgID.Hex()
+	}
+	token, expiresAt, err := tokenIssuer.Issue(claims)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"token":     token,
+		"expiresAt": expiresAt,
+		"user":      user,
+	}, nil
+}
+
+// Signup creates an account in the request's organization and logs it in.
+func Signup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var credentials models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := credentials.ValidateSignup(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hash, err := auth.HashPassword(credentials.Password)
+	if err != nil {
+		log.Println("Signup failed:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create account")
+		return
+	}
+
+	now := time.Now()
+	user := &models.UserAccount{
+		OrgID:        requestTenant(r).orgId,
+		Name:         credentials.Name,
+		Email:        credentials.Email,
+		Role:         models.RoleCandidate,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := userStore.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			utils.ErrorResponse(w, http.StatusConflict, "An account with this email already exists")
+			return
+		}
+		log.Println("Signup failed:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create account")
+		return
+	}
+
+	response, err := authResponse(user)
+	if err != nil {
+		log.Println("Failed to issue token:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, "Account created successfully", response)
+}
+
+// Login exchanges an email and password for an access token. Accounts are
+// looked up in the request's organization.
+func Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var credentials models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := userStore.GetUserByEmail(ctx, requestTenant(r).orgId, models.NormalizeEmail(credentials.Email))
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		log.Println("Login failed:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+
+	// Unknown emails and wrong passwords get the same answer
+	if user == nil {
+		auth.RejectPassword(credentials.Password)
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, credentials.Password) {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+
+	response, err := authResponse(user)
+	if err != nil {
+		log.Println("Failed to issue token:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+	utils.SuccessResponse(w, "Logged in successfully", response)
+}
+
+// GetCurrentUser returns the account of the authenticated caller.
+func GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userId, ok := authenticatedUser(r)
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := userStore.GetUser(ctx, userId)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Account no longer exists")
+			return
+		}
+		log.Println("Failed to fetch user:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+	utils.SuccessResponse(w, "User retrieved successfully", user)
+}
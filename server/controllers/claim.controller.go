@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/auth"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+)
+
+var auditStore store.AuditStore
+
+// recordAuditEvent appends event to the audit trail. A failure is logged
+// rather than undoing what the event records.
+func recordAuditEvent(event *models.AuditEvent) {
+	if auditStore == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event.CreatedAt = time.Now()
+	if err := auditStore.RecordEvent(ctx, event); err != nil {
+		log.Printf("Failed to record %s event for session %s: %v", event.Action, event.SessionID.Hex(), err)
+	}
+}
+
+// ClaimSession moves a guest session into the authenticated user's account.
+// The caller proves they started it with the session's guest token, which
+// stops working once the session is claimed.
+func ClaimSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userId, ok := authenticatedUser(r)
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Log in to claim a session")
+		return
+	}
+
+	session, err := GetSession(requestTenant(r).orgId, mux.Vars(r)["sessionId"])
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to claim session")
+		return
+	}
+
+	// Claiming a session twice is harmless
+	if session.UserType == models.User && session.UserID == userId {
+		utils.SuccessResponse(w, "Session already claimed", session)
+		return
+	}
+
+	guestToken := r.Header.Get(GuestTokenHeader)
+	if session.UserType != models.Guest || !auth.CheckGuestToken(session.GuestTokenHash, guestToken) {
+		sessionErrorResponse(w, errSessionNotFound, "Failed to claim session")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	claimed, err := sessionStore.ClaimSession(ctx, session.OrgID, session.ID, session.GuestTokenHash, userId, time.Now())
+	if err != nil {
+		// Someone else claimed it first with the same token
+		if errors.Is(err, store.ErrConflict) {
+			err = errSessionNotFound
+		}
+		sessionErrorResponse(w, err, "Failed to claim session")
+		return
+	}
+
+	recordAuditEvent(&models.AuditEvent{
+		OrgID:     claimed.OrgID,
+		Action:    models.AuditSessionClaimed,
+		ActorID:   userId,
+		SessionID: claimed.ID,
+		Details: map[string]string{
+			"fromUserType":    string(models.Guest),
+			"interviewStatus": string(claimed.InterviewStatus),
+		},
+	})
+
+	utils.SuccessResponse(w, "Session claimed successfully", claimed)
+}
+
+// GetSessionAudit returns the audit trail of a session to its owner and the
+// organization's reviewers.
+func GetSessionAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	session, err := getViewableSession(r, mux.Vars(r)["sessionId"])
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to get audit trail")
+		return
+	}
+
+	events := []models.AuditEvent{}
+	if auditStore != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		events, err = auditStore.ListEvents(ctx, session.OrgID, session.ID)
+		if err != nil {
+			log.Println("Failed to fetch audit trail:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get audit trail")
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "Audit trail retrieved successfully", map[string]interface{}{
+		"sessionId": session.ID.Hex(),
+		"events":    events,
+	})
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SessionLifetime bounds how long a session stays usable. A zero duration
+// disables that bound.
+type SessionLifetime struct {
+	// Inactivity is how long a session may go without being updated.
+	Inactivity time.Duration
+	// Absolute is how long a session may exist at all, not counting time
+	// spent paused.
+	Absolute time.Duration
+	// MaxPause is how long a session may stay paused.
+	MaxPause time.Duration
+	// SweepInterval is how often the sweeper looks for stale sessions.
+	SweepInterval time.Duration
+}
+
+// sessionLifetime is replaced by StartExpirySweeper with the configured
+// lifetime.
+var sessionLifetime = defaultSessionLifetime()
+
+func defaultSessionLifetime() SessionLifetime {
+	return SessionLifetime{
+		Inactivity:    30 * time.Minute,
+		Absolute:      3 * time.Hour,
+		MaxPause:      24 * time.Hour,
+		SweepInterval: time.Minute,
+	}
+}
+
+// SessionLifetimeFromEnv returns the default lifetime with any
+// SESSION_INACTIVITY_TIMEOUT, SESSION_MAX_LIFETIME, SESSION_MAX_PAUSE and
+// SESSION_SWEEP_INTERVAL overrides applied.
+func SessionLifetimeFromEnv() SessionLifetime {
+	lifetime := defaultSessionLifetime()
+
+	durationEnv := func(key string, target *time.Duration) {
+		if v := os.Getenv(key); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+				*target = d
+			} else {
+				log.Printf("Warning: invalid %s %q", key, v)
+			}
+		}
+	}
+	durationEnv("SESSION_INACTIVITY_TIMEOUT", &lifetime.Inactivity)
+	durationEnv("SESSION_MAX_LIFETIME", &lifetime.Absolute)
+	durationEnv("SESSION_MAX_PAUSE", &lifetime.MaxPause)
+	durationEnv("SESSION_SWEEP_INTERVAL", &lifetime.SweepInterval)
+
+	return lifetime
+}
+
+// cutoffs returns the times before which a session is stale at now.
+// Disabled bounds are zero.
+func (l SessionLifetime) cutoffs(now time.Time) store.ExpiryCutoffs {
+	var cutoffs store.ExpiryCutoffs
+	if l.Inactivity > 0 {
+		cutoffs.InactiveBefore = now.Add(-l.Inactivity)
+	}
+	if l.Absolute > 0 {
+		cutoffs.StartedBefore = now.Add(-l.Absolute)
+	}
+	if l.MaxPause > 0 {
+		cutoffs.PausedBefore = now.Add(-l.MaxPause)
+	}
+	return cutoffs
+}
+
+// isStale reports whether session has outlived the lifetime at now.
+func (l SessionLifetime) isStale(session *models.Session, now time.Time) bool {
+	return !session.InterviewStatus.IsTerminal() && l.cutoffs(now).IsStale(session)
+}
+
+// expiredFields are set on a session as it expires.
+func expiredFields(now time.Time) bson.M {
+	return bson.M{"hasExpired": true, "expiredAt": now}
+}
+
+// expireIfStale expires session if it is stale but the sweeper has not got
+// to it yet, returning the session as stored.
+func expireIfStale(session *models.Session) (*models.Session, error) {
+	now := time.Now()
+	if !sessionLifetime.isStale(session, now) {
+		return session, nil
+	}
+	return TransitionSession(session, models.Expired, expiredFields(now))
+}
+
+// sweepExpiredSessions expires every stale session once.
+func sweepExpiredSessions(ctx context.Context) {
+	now := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	fields := expiredFields(now)
+	fields["updatedAt"] = now
+
+	count, err := sessionStore.ExpireSessions(ctx, models.StatusesLeadingTo(models.Expired), sessionLifetime.cutoffs(now), fields)
+	if err != nil {
+		log.Println("Session sweep failed:", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Expired %d stale sessions", count)
+	}
+}
+
+// StartExpirySweeper applies lifetime to every session and expires stale
+// sessions every SweepInterval until ctx is done.
+func StartExpirySweeper(ctx context.Context, lifetime SessionLifetime) {
+	sessionLifetime = lifetime
+
+	if sessionLifetime.SweepInterval <= 0 || (sessionLifetime.Inactivity <= 0 && sessionLifetime.Absolute <= 0 && sessionLifetime.MaxPause <= 0) {
+		log.Println("Session expiry sweeper disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(sessionLifetime.SweepInterval)
+		defer ticker.Stop()
+
+		sweepExpiredSessions(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sweepExpiredSessions(ctx)
+			}
+		}
+	}()
+}
@@ -3,86 +3,214 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"regexp"
+	"os"
+	"strconv"
 	"strings"
 	"time"
-	"os"
-
-	"google.golang.org/api/option"
 
-	"github.com/google/generative-ai-go/genai"
 	"github.com/gorilla/mux"
 	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
 	"github.com/rnkp755/mockinterviewBackend/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var model *genai.GenerativeModel
-var client *genai.Client
+var (
+	llmProvider llm.LLMProvider
+	// llmPolicy is the policy llmProvider enforces. It bounds how long
+	// handlers wait on the model.
+	llmPolicy = llm.DefaultPolicy()
+)
+
+// writeDeadlineSlack is the time allowed on top of the model calls for
+// everything else a handler does before its response is written.
+const writeDeadlineSlack = 15 * time.Second
 
 // GeminiRequest struct handles the incoming JSON body
 type GeminiRequest struct {
 	Answer string `json:"answer"`
+	Source string `json:"source"`
+	// Turn optionally names the turn being answered; the request is
+	// rejected unless it is the current one.
+	Turn int `json:"turn"`
 }
 
-func init() {
-	ctx := context.Background()
+// SetLLMProvider sets the backend used for interview turns and resume parsing.
+func SetLLMProvider(provider llm.LLMProvider) {
+	llmProvider = provider
+}
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		log.Println("Warning: GEMINI_API_KEY not set. Gemini features will not work.")
-		return
+// SetLLMPolicy sets the deadlines and retries the LLM provider was wrapped
+// with.
+func SetLLMPolicy(policy llm.Policy) {
+	llmPolicy = policy
+}
+
+// llmBudget is the longest calls model calls of op can take, retries
+// included. Zero means they are unbounded.
+func llmBudget(op llm.Operation, calls int) time.Duration {
+	return time.Duration(calls) * llmPolicy.Timeout(op)
+}
+
+// extendWriteDeadline lets a handler that waits on calls model calls of op
+// write its response after the server's WriteTimeout, which only suits
+// requests that do not call the model.
+func extendWriteDeadline(w http.ResponseWriter, op llm.Operation, calls int) {
+	var deadline time.Time
+	if budget := llmBudget(op, calls); budget > 0 {
+		deadline = time.Now().Add(budget + writeDeadlineSlack)
 	}
+	if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+		log.Println("Failed to extend write deadline:", err)
+	}
+}
 
-	var err error
-	client, err = genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		log.Println("Failed to create Gemini client:", err)
-		return
+// stripCodeFence removes a markdown code fence wrapped around a JSON reply.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	return strings.TrimSpace(text)
+}
+
+// parseInterviewerResponse decodes and validates the model's JSON reply.
+func parseInterviewerResponse(response string, requireEvaluation bool) (models.ExtractedResponse, error) {
+	var result models.ExtractedResponse
+	if err := json.Unmarshal([]byte(stripCodeFence(response)), &result); err != nil {
+		return result, fmt.Errorf("malformed interviewer response: invalid JSON: %v", err)
 	}
 
-	model = client.GenerativeModel("gemini-2.5-flash")
+	result.Question = strings.TrimSpace(result.Question)
+	result.Code = strings.TrimSpace(result.Code)
+
+	if err := result.Validate(requireEvaluation); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
-// extractPartsFromGeminiResponse parses the text output using Regex
-func extractPartsFromGeminiResponse(response string) models.ExtractedResponse {
-	result := models.ExtractedResponse{}
+// completedJSONFields returns the top-level fields of a partially streamed
+// JSON object whose values are already complete.
+func completedJSONFields(text string) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+
+	start := strings.Index(text, "{")
+	if start < 0 {
+		return fields
+	}
+	text = text[start:]
 
-	extract := func(tag string) string {
-		re := regexp.MustCompile(fmt.Sprintf(`<%s>(.*?)</%s>`, tag, tag))
-		matches := re.FindStringSubmatch(response)
-		if len(matches) > 1 {
-			return strings.TrimSpace(matches[1])
+	dec := json.NewDecoder(strings.NewReader(text))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fields
+	}
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return fields
+		}
+		key, ok := keyTok.(string)
+		if !ok {
+			return fields
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fields
+		}
+
+		// A number at the very end of the buffer may still be growing.
+		isNumber := raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9')
+		if isNumber && dec.InputOffset() >= int64(len(text)) {
+			return fields
 		}
-		return ""
+		fields[key] = raw
+	}
+	return fields
+}
+
+// interviewTurn holds everything needed to run one interviewer turn.
+// session is the session as it was when the turn started; evaluating is the
+// same session after it moved to the evaluating status.
+type interviewTurn struct {
+	sessionId  string
+	session    *models.Session
+	evaluating *models.Session
+	questions  *models.Question
+	answer     string
+	source     models.AnswerSource
+	prompt     string
+	requestKey string
+
+	// replay is set when the request repeats an Idempotency-Key whose turn
+	// was already stored; it is answered without calling the LLM.
+	replay *turnResult
+}
+
+// nextNumber is the number of the question this turn asks.
+func (t *interviewTurn) nextNumber() int {
+	if current := t.questions.CurrentTurn(); current != nil {
+		return current.Number + 1
 	}
+	return 1
+}
 
-	result.Rating = extract("Rating")
-	result.Feedback = extract("Feedback")
-	result.Question = extract("Question")
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
 
-	codeRe := regexp.MustCompile(`(?s)<Code>(.*?)</Code>`)
-	codeMatches := codeRe.FindStringSubmatch(response)
-	if len(codeMatches) > 1 {
-		result.Code = strings.TrimSpace(codeMatches[1])
+// replayedTurn rebuilds the response of the request that asked turn. The
+// answer must match the one given with the original request.
+func replayedTurn(questions *models.Question, turn *models.Turn, answer string) (*turnResult, error) {
+	result := &turnResult{
+		parts:    models.ExtractedResponse{Question: turn.Question, Code: turn.Code},
+		attempts: turn.Attempts,
+		model:    turn.Model,
+		number:   turn.Number,
+
+		difficulty: turn.Difficulty,
+	}
+	if turn.Number == 1 {
+		return result, nil
 	}
 
-	return result
+	answered := &questions.Turns[turn.Number-2]
+	if answered.Answer != nil && answered.Answer.Text != answer {
+		return nil, errIdempotencyKeyReused
+	}
+	result.parts.Rating = answered.Rating
+	result.parts.Feedback = answered.Feedback
+	return result, nil
 }
 
-func AskToGemini(w http.ResponseWriter, r *http.Request) {
-	log.Println("----- Received AskToGemini Request -----")
+// errIdempotencyKeyReused is returned when an Idempotency-Key is sent again
+// with a different answer.
+var errIdempotencyKeyReused = errors.New("Idempotency-Key was already used with a different answer")
 
+// isFirstQuestion reports whether the turn opens the interview, in which case
+// there is no answer to evaluate.
+func (t *interviewTurn) isFirstQuestion() bool {
+	return t.session.InterviewStatus == models.NotStarted
+}
+
+// prepareInterviewTurn parses the candidate's answer, validates the session
+// and builds the prompt. It writes an error response and returns false if the
+// turn cannot go ahead.
+func prepareInterviewTurn(w http.ResponseWriter, r *http.Request) (*interviewTurn, bool) {
 	if r.Method != http.MethodPost {
 		utils.ErrorResponse(w, http.StatusMethodNotAllowed, "Invalid request method")
-		return
+		return nil, false
 	}
 
-	var answer string
+	var answer, source string
+	var expectedTurn int
 	contentType := r.Header.Get("Content-Type")
 	log.Printf("Content-Type: %s", contentType)
 
@@ -91,27 +219,18 @@ func AskToGemini(w http.ResponseWriter, r *http.Request) {
 		var reqBody GeminiRequest
 		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
 			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON body")
-			return
+			return nil, false
 		}
 		answer = reqBody.Answer
+		source = reqBody.Source
+		expectedTurn = reqBody.Turn
 	} else if strings.Contains(contentType, "multipart/form-data") {
 		// Parse up to 10MB
 		if err := r.ParseMultipartForm(10 << 20); err != nil {
 			log.Printf("Error parsing multipart: %v", err)
 			utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
-			return
-		}
-
-		// DEBUG: Print ALL keys received to find the mismatch
-		log.Println("--- Printing All Received Form Keys ---")
-		if r.MultipartForm != nil && r.MultipartForm.Value != nil {
-			for key, values := range r.MultipartForm.Value {
-				log.Printf("Key: [%s] | Value Length: %d | First Value: %s", key, len(values[0]), values[0])
-			}
-		} else {
-			log.Println("Warning: MultipartForm is empty!")
+			return nil, false
 		}
-		log.Println("---------------------------------------")
 
 		// Try to find the answer using multiple common keys
 		answer = r.FormValue("answer")
@@ -120,12 +239,37 @@ func AskToGemini(w http.ResponseWriter, r *http.Request) {
 		}
 		if answer == "" {
 			answer = r.FormValue("transcript") // Try 'transcript'
+			if answer != "" {
+				source = string(models.AnswerSpeech)
+			}
+		}
+		if s := r.FormValue("source"); s != "" {
+			source = s
 		}
+		expectedTurn, _ = strconv.Atoi(r.FormValue("turn"))
 
 	} else {
 		// Fallback for standard form encoding
 		r.ParseForm()
 		answer = r.FormValue("answer")
+		source = r.FormValue("source")
+		expectedTurn, _ = strconv.Atoi(r.FormValue("turn"))
+	}
+
+	requestKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if len(requestKey) > maxIdempotencyKeyLength {
+		utils.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+		return nil, false
+	}
+
+	// The client marks code written in the IDE with [CODE_SUBMISSION]
+	if source == "" && strings.Contains(answer, "[CODE_SUBMISSION]") {
+		source = string(models.AnswerCode)
+	}
+	answerSource, err := models.ParseAnswerSource(source)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return nil, false
 	}
 
 	// --- 2. VALIDATION ---
@@ -134,15 +278,37 @@ func AskToGemini(w http.ResponseWriter, r *http.Request) {
 	sessionId := vars["sessionId"]
 
 	// Get Session
-	session, err := GetSession(sessionId)
+	session, err := getOwnedSession(r, sessionId)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get session")
-		return
+		sessionErrorResponse(w, err, "Failed to get session")
+		return nil, false
 	}
 
-	if session.InterviewStatus == models.Ended {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Session has already ended")
-		return
+	// A retried request gets the stored response instead of a new turn
+	if requestKey != "" && session.InterviewStatus != models.NotStarted {
+		if history, err := GetQuestion(session.OrgID, session.ID.Hex()); err == nil {
+			if stored := history.TurnByRequestKey(requestKey); stored != nil {
+				replay, err := replayedTurn(history, stored, answer)
+				if err != nil {
+					utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+					return nil, false
+				}
+				log.Printf("Replaying turn %d for Idempotency-Key %q", stored.Number, requestKey)
+				return &interviewTurn{sessionId: sessionId, session: session, questions: history, replay: replay}, true
+			}
+		}
+	}
+
+	// Fail fast before doing any work; the transition below re-checks this
+	// atomically.
+	if err := session.InterviewStatus.Transition(models.Evaluating); err != nil {
+		sessionErrorResponse(w, err, "Failed to get session")
+		return nil, false
+	}
+
+	if err := checkBudgets(session, requestTenant(r).config()); err != nil {
+		utils.ErrorResponse(w, http.StatusTooManyRequests, err.Error())
+		return nil, false
 	}
 
 	// Check if answer is required
@@ -150,76 +316,344 @@ func AskToGemini(w http.ResponseWriter, r *http.Request) {
 		if strings.TrimSpace(answer) == "" {
 			log.Println("Error: Answer is empty but required for this stage.")
 			utils.ErrorResponse(w, http.StatusBadRequest, "Please provide an answer (Received empty string)")
-			return
+			return nil, false
 		}
 	}
 
-	// ... (Rest of the logic: Questions, PromptGenerator, Gemini API call) ...
-	// The rest of your code works fine once 'answer' is populated.
-	
-	// Copy-paste the rest of your logic here:
-	var prompt string
+	// --- 3. PROMPT ---
+	var questions *models.Question
 	if session.InterviewStatus != models.NotStarted {
-		questions, err := GetQuestion(session.ID.Hex())
-		if err != nil {
+		questions, err = GetQuestion(session.OrgID, session.ID.Hex())
+		if err != nil || questions.CurrentTurn() == nil {
 			log.Printf("Error getting questions: %v", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load interview history")
+			return nil, false
 		}
-		prompt = utils.PromptGenerator(session, questions, answer)
-	} else {
-		prompt = utils.PromptGenerator(session, nil, "")
 	}
 
-	// Gemini Call
-	log.Println("Sending prompt to Gemini...")
-	resp, err := model.GenerateContent(r.Context(), genai.Text(prompt))
+	// The client may say which turn it is answering, so an answer meant for
+	// an earlier question is never applied to a later one
+	if expectedTurn > 0 {
+		if current := questions.CurrentTurn(); current == nil || current.Number != expectedTurn {
+			utils.ErrorResponse(w, http.StatusConflict, fmt.Sprintf("Turn %d is not the current turn", expectedTurn))
+			return nil, false
+		}
+	}
+
+	prompt := utils.PromptGenerator(session, questions, answer)
+
+	if llmProvider == nil {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, "LLM provider not initialized")
+		return nil, false
+	}
+
+	// Claim the session for this turn. A concurrent turn or end request
+	// makes this fail with a conflict.
+	evaluating, err := TransitionSession(session, models.Evaluating, nil)
 	if err != nil {
-		log.Printf("Gemini Error: %v", err)
-		utils.ErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Error generating content: %v", err))
-		return
+		sessionErrorResponse(w, err, "Failed to start interview turn")
+		return nil, false
 	}
 
-	// Response Parsing
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Empty response from Gemini")
-		return
+	return &interviewTurn{
+		sessionId:  sessionId,
+		session:    session,
+		evaluating: evaluating,
+		questions:  questions,
+		answer:     answer,
+		source:     answerSource,
+		prompt:     prompt,
+		requestKey: requestKey,
+	}, true
+}
+
+// abortInterviewTurn returns the session to the status it had before the
+// turn, so the candidate can try again.
+func abortInterviewTurn(turn *interviewTurn) {
+	if _, err := TransitionSession(turn.evaluating, turn.session.InterviewStatus, nil); err != nil {
+		log.Printf("Failed to restore session status after failed turn: %v", err)
 	}
-	
-	part := resp.Candidates[0].Content.Parts[0]
-	textResp, ok := part.(genai.Text)
-	if !ok {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Unexpected response format")
+}
+
+// turnResult is a validated interviewer reply. number is the number of the
+// question it asks.
+type turnResult struct {
+	parts    models.ExtractedResponse
+	attempts int
+	model    string
+	number   int
+	// difficulty is the level of the next question, and ability the
+	// session's ability estimate after the answer's rating.
+	difficulty int
+	ability    *float64
+}
+
+// adaptDifficulty sets the level of the next question from the rating of
+// the current answer, or from the starting ability for the first question.
+func (t *interviewTurn) adaptDifficulty(result *turnResult) {
+	plan := models.PlanDifficulty(t.session, t.questions)
+	if t.isFirstQuestion() || result.parts.Rating == nil {
+		result.difficulty = plan.Current
 		return
 	}
+	ability, difficulty := plan.Next(*result.parts.Rating)
+	result.ability = &ability
+	result.difficulty = difficulty
+}
 
-	extractedParts := extractPartsFromGeminiResponse(string(textResp))
-	
-	// Save to DB
-	fullQuestionText := extractedParts.Question
-	if extractedParts.Code != "" {
-		fullQuestionText += "\n```\n" + extractedParts.Code + "\n```"
+// saveInterviewTurn persists the interviewer's reply for the turn: the
+// evaluation is stored on the current turn and the next question becomes a
+// new turn.
+func saveInterviewTurn(turn *interviewTurn, result turnResult) error {
+	now := time.Now()
+	next := models.Turn{
+		Number:     turn.nextNumber(),
+		Question:   result.parts.Question,
+		Code:       result.parts.Code,
+		AskedAt:    now,
+		Model:      result.model,
+		Attempts:   result.attempts,
+		Difficulty: result.difficulty,
+		RequestKey: turn.requestKey,
 	}
+	var fields bson.M
 
-	if session.InterviewStatus == models.NotStarted {
-		UpdateSession(sessionId, bson.M{"interviewstatus": "waiting-for-answer"})
+	if turn.isFirstQuestion() {
 		question := models.Question{
 			ID:        primitive.NewObjectID(),
-			SessionId: session.ID,
-			Question:  []string{fullQuestionText},
-			Rating:    []string{},
-			Review:    []string{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		AddQuestion(question)
+			OrgID:     turn.session.OrgID,
+			SessionId: turn.session.ID,
+			Turns:     []models.Turn{next},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if _, err := AddQuestion(question); err != nil {
+			return err
+		}
 	} else {
-		UpdateQuestion(fullQuestionText, extractedParts.Rating, extractedParts.Feedback, sessionId)
+		answered := *turn.questions.CurrentTurn()
+		answered.Answer = &models.Answer{Text: turn.answer, Source: turn.source}
+		answered.AnsweredAt = &now
+		answered.Rating = result.parts.Rating
+		answered.Feedback = result.parts.Feedback
+		answered.GraderModel = result.model
+		answered.PromptVersion = utils.PromptVersion
+		answered.Ability = result.ability
+		if result.ability != nil {
+			fields = bson.M{"ability": *result.ability}
+		}
+
+		if _, err := UpdateQuestion(turn.session.OrgID, turn.sessionId, answered, next); err != nil {
+			return err
+		}
+	}
+
+	_, err := TransitionSession(turn.evaluating, models.WaitingForAnswer, fields)
+	return err
+}
+
+func turnResponse(result turnResult) map[string]interface{} {
+	return map[string]interface{}{
+		"question":   result.parts.Question,
+		"code":       result.parts.Code,
+		"rating":     result.parts.Rating,
+		"feedback":   result.parts.Feedback,
+		"attempts":   result.attempts,
+		"turn":       result.number,
+		"difficulty": result.difficulty,
+	}
+}
+
+// errMalformedResponse is returned once every repair attempt has produced an
+// invalid interviewer response.
+var errMalformedResponse = errors.New("interviewer returned an invalid response")
+
+// interviewAttempts is the maximum number of model calls per turn, including
+// the first one. It is configured with LLM_MAX_ATTEMPTS.
+func interviewAttempts() int {
+	if n, err := strconv.Atoi(os.Getenv("LLM_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return 3
+}
+
+// generateValidTurn calls generate until the model returns a valid response,
+// re-prompting with a corrective instruction after each invalid one.
+func generateValidTurn(turn *interviewTurn, generate func(prompt string, attempt int) (*llm.Response, error)) (turnResult, error) {
+	maxAttempts := interviewAttempts()
+	prompt := turn.prompt
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err := generate(prompt, attempt)
+		if err != nil {
+			return turnResult{attempts: attempt}, err
+		}
+		recordUsage(turn.session.OrgID, turn.session.ID, llm.OpInterview, resp)
+
+		extractedParts, err := parseInterviewerResponse(resp.Text, !turn.isFirstQuestion())
+		if err == nil {
+			result := turnResult{parts: extractedParts, attempts: attempt, model: resp.Model, number: turn.nextNumber()}
+			turn.adaptDifficulty(&result)
+			return result, nil
+		}
+
+		log.Printf("Attempt %d/%d rejected: %v", attempt, maxAttempts, err)
+		lastErr = err
+		prompt = utils.RepairPrompt(turn.prompt, resp.Text, err)
+	}
+
+	return turnResult{attempts: maxAttempts},
+		fmt.Errorf("%w after %d attempts: %v", errMalformedResponse, maxAttempts, lastErr)
+}
+
+// turnErrorStatus maps a failed LLM call to the matching status code.
+func turnErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, llm.ErrCircuitOpen):
+		return http.StatusServiceUnavailable, err.Error()
+	case errors.Is(err, errMalformedResponse):
+		return http.StatusBadGateway, err.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "LLM request timed out"
+	}
+	return http.StatusInternalServerError, fmt.Sprintf("Error generating content: %v", err)
+}
+
+// llmErrorResponse writes a failed LLM call as a JSON error, advertising
+// Retry-After when the provider circuit is open.
+func llmErrorResponse(w http.ResponseWriter, err error) {
+	var open *llm.CircuitOpenError
+	if errors.As(err, &open) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(open.RetryAfter.Seconds()))))
+	}
+
+	status, message := turnErrorStatus(err)
+	utils.ErrorResponse(w, status, message)
+}
+
+func AskToGemini(w http.ResponseWriter, r *http.Request) {
+	log.Println("----- Received AskToGemini Request -----")
+	extendWriteDeadline(w, llm.OpInterview, interviewAttempts())
+
+	turn, ok := prepareInterviewTurn(w, r)
+	if !ok {
+		return
+	}
+
+	if turn.replay != nil {
+		w.Header().Set("Content-Type", "application/json")
+		utils.SuccessResponse(w, "Gemini response retrieved successfully", turnResponse(*turn.replay))
+		return
+	}
+
+	// LLM Call
+	ctx := llm.WithSession(llm.WithOperation(r.Context(), llm.OpInterview), turn.session.ID.Hex())
+	log.Printf("Sending prompt to %s...", llmProvider.Name())
+	schema := llm.WithSchema(utils.ResponseSchema(turn.isFirstQuestion()))
+	model := llm.WithModel(turn.session.Model)
+	result, err := generateValidTurn(turn, func(prompt string, attempt int) (*llm.Response, error) {
+		return llmProvider.GenerateText(ctx, prompt, schema, model)
+	})
+	if err != nil {
+		log.Printf("LLM Error: %v", err)
+		abortInterviewTurn(turn)
+		llmErrorResponse(w, err)
+		return
+	}
+	if err := saveInterviewTurn(turn, result); err != nil {
+		log.Printf("Failed to save turn: %v", err)
+		abortInterviewTurn(turn)
+		sessionErrorResponse(w, err, "Failed to save interview turn")
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	utils.SuccessResponse(w, "Gemini response retrieved successfully", map[string]interface{}{
-		"question": extractedParts.Question,
-		"code":     extractedParts.Code,
-		"rating":   extractedParts.Rating,
-		"feedback": extractedParts.Feedback,
+	utils.SuccessResponse(w, "Gemini response retrieved successfully", turnResponse(result))
+}
+
+// streamedFields are the response fields pushed to the client as soon as they
+// are complete. Each is sent as an event of the same name.
+var streamedFields = []string{"rating", "feedback", "question", "code"}
+
+// AskToGeminiStream is the Server-Sent Events variant of AskToGemini. It emits
+// "question", "code", "rating" and "feedback" events as the corresponding JSON
+// fields complete, followed by a "done" event carrying the same payload
+// AskToGemini returns. If a response is rejected a "retry" event is sent and
+// the fields are streamed again from the repaired response.
+func AskToGeminiStream(w http.ResponseWriter, r *http.Request) {
+	log.Println("----- Received AskToGeminiStream Request -----")
+	extendWriteDeadline(w, llm.OpInterview, interviewAttempts())
+
+	turn, ok := prepareInterviewTurn(w, r)
+	if !ok {
+		return
+	}
+
+	sse, err := utils.NewSSEWriter(w)
+	if err != nil {
+		if turn.replay == nil {
+			abortInterviewTurn(turn)
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if turn.replay != nil {
+		response := turnResponse(*turn.replay)
+		for _, field := range streamedFields {
+			if err := sse.Send(field, response[field]); err != nil {
+				return
+			}
+		}
+		sse.Send("done", response)
+		return
+	}
+
+	ctx := llm.WithSession(llm.WithOperation(r.Context(), llm.OpInterview), turn.session.ID.Hex())
+	log.Printf("Streaming prompt to %s...", llmProvider.Name())
+	schema := llm.WithSchema(utils.ResponseSchema(turn.isFirstQuestion()))
+	model := llm.WithModel(turn.session.Model)
+	result, err := generateValidTurn(turn, func(prompt string, attempt int) (*llm.Response, error) {
+		if attempt > 1 {
+			if err := sse.Send("retry", map[string]int{"attempt": attempt}); err != nil {
+				return nil, err
+			}
+		}
+
+		var buffer strings.Builder
+		sent := map[string]bool{}
+
+		return llmProvider.GenerateTextStream(ctx, prompt, func(chunk string) error {
+			buffer.WriteString(chunk)
+			fields := completedJSONFields(buffer.String())
+
+			for _, field := range streamedFields {
+				raw, done := fields[field]
+				if sent[field] || !done {
+					continue
+				}
+				sent[field] = true
+				if err := sse.Send(field, raw); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, schema, model)
 	})
-}
\ No newline at end of file
+	if err != nil {
+		log.Printf("LLM Stream Error: %v", err)
+		abortInterviewTurn(turn)
+		sse.SendError(turnErrorStatus(err))
+		return
+	}
+
+	if err := saveInterviewTurn(turn, result); err != nil {
+		log.Printf("Failed to save turn: %v", err)
+		abortInterviewTurn(turn)
+		sse.SendError(http.StatusInternalServerError, "Failed to save interview turn")
+		return
+	}
+
+	sse.Send("done", turnResponse(result))
+}
@@ -0,0 +1,324 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/controllers"
+	"github.com/rnkp755/mockinterviewBackend/routes"
+	"github.com/rnkp755/mockinterviewBackend/services/auth"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+	"github.com/rnkp755/mockinterviewBackend/store"
+)
+
+// newTestServer serves the API from memory stores and the given provider.
+func newTestServer(t *testing.T, provider llm.LLMProvider) *httptest.Server {
+	t.Helper()
+
+	controllers.SetStores(store.NewMemoryStores())
+	controllers.SetLLMProvider(provider)
+	controllers.SetLLMPolicy(llm.DefaultPolicy())
+	controllers.SetTokenIssuer(auth.NewTokenIssuer([]byte(strings.Repeat("k", 32)), time.Hour))
+
+	server := httptest.NewServer(routes.Router())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// apiResponse is the envelope of every JSON response.
+type apiResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// send makes a JSON request and decodes the response envelope.
+func send(t *testing.T, method, url string, body string, headers map[string]string) (*http.Response, apiResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("%s %s: decoding response: %v", method, url, err)
+	}
+	return resp, decoded
+}
+
+// createGuestSession creates a guest session and returns its ID and guest
+// token.
+func createGuestSession(t *testing.T, server *httptest.Server) (string, string) {
+	t.Helper()
+
+	resp, body := send(t, http.MethodPost, server.URL+"/api/v1/session",
+		`{"userType": "guest", "name": "Jane", "experience": "Fresher", "techStacks": ["Go"]}`, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("creating session: status %d: %s", resp.StatusCode, body.Message)
+	}
+
+	var sessionId string
+	if err := json.Unmarshal(body.Data, &sessionId); err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	return sessionId, resp.Header.Get(controllers.GuestTokenHeader)
+}
+
+const invalidReply = "I would rather not answer in JSON."
+
+const firstQuestionReply = `{"question": "Hello! What is a goroutine?", "code": ""}`
+
+// interviewStep is one request of an interview and what it must answer.
+type interviewStep struct {
+	// path follows /api/v1/ and has the session ID substituted for %s.
+	path string
+	body string
+	// headers are sent on top of the session's guest token unless noToken
+	// is set.
+	headers    map[string]string
+	noToken    bool
+	wantStatus int
+	// want lists fields of the response data and their JSON encoding.
+	want map[string]string
+}
+
+func TestInterviewFlow(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []string
+		steps  []interviewStep
+	}{
+		{
+			name: "plays a full interview and reports on it",
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusOK,
+					want: map[string]string{"turn": "1", "rating": "null", "attempts": "1", "difficulty": "2"}},
+				{path: "ask-to-gemini/%s", body: `{"answer": "Threads share memory."}`, wantStatus: http.StatusOK,
+					want: map[string]string{"turn": "2", "rating": "7"}},
+				{path: "ask-to-gemini/%s", body: `{"answer": "O(n)", "turn": 2}`, wantStatus: http.StatusOK,
+					want: map[string]string{"turn": "3", "rating": "8"}},
+				{path: "end/%s", wantStatus: http.StatusOK},
+				// Ended sessions take no more answers
+				{path: "ask-to-gemini/%s", body: `{"answer": "Token bucket"}`, wantStatus: http.StatusConflict},
+			},
+		},
+		{
+			name: "needs the guest token",
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, noToken: true, wantStatus: http.StatusNotFound},
+			},
+		},
+		{
+			name: "requires an answer after the first question",
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusOK},
+				{path: "ask-to-gemini/%s", body: `{"answer": "  "}`, wantStatus: http.StatusBadRequest},
+			},
+		},
+		{
+			name: "rejects an answer meant for another turn",
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusOK},
+				{path: "ask-to-gemini/%s", body: `{"answer": "late", "turn": 3}`, wantStatus: http.StatusConflict},
+			},
+		},
+		{
+			name:   "re-prompts after a malformed reply",
+			script: []string{invalidReply, firstQuestionReply},
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusOK,
+					want: map[string]string{"turn": "1", "attempts": "2", "question": `"Hello! What is a goroutine?"`}},
+			},
+		},
+		{
+			name:   "gives up on malformed replies and lets the candidate retry",
+			script: []string{invalidReply, invalidReply, invalidReply, firstQuestionReply},
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusBadGateway},
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusOK,
+					want: map[string]string{"turn": "1"}},
+			},
+		},
+		{
+			name: "replays a retried request",
+			steps: []interviewStep{
+				{path: "ask-to-gemini/%s", body: `{}`, wantStatus: http.StatusOK},
+				{path: "ask-to-gemini/%s", body: `{"answer": "first try"}`, headers: map[string]string{"Idempotency-Key": "k1"},
+					wantStatus: http.StatusOK, want: map[string]string{"turn": "2", "rating": "7"}},
+				{path: "ask-to-gemini/%s", body: `{"answer": "first try"}`, headers: map[string]string{"Idempotency-Key": "k1"},
+					wantStatus: http.StatusOK, want: map[string]string{"turn": "2", "rating": "7"}},
+				{path: "ask-to-gemini/%s", body: `{"answer": "second try"}`, headers: map[string]string{"Idempotency-Key": "k1"},
+					wantStatus: http.StatusUnprocessableEntity},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := llm.NewScriptedProvider()
+			if tt.script != nil {
+				provider.TextScript = tt.script
+			}
+			server := newTestServer(t, provider)
+			sessionId, guestToken := createGuestSession(t, server)
+
+			for i, step := range tt.steps {
+				headers := map[string]string{controllers.GuestTokenHeader: guestToken}
+				if step.noToken {
+					headers = map[string]string{}
+				}
+				for key, value := range step.headers {
+					headers[key] = value
+				}
+
+				url := server.URL + "/api/v1/" + fmt.Sprintf(step.path, sessionId)
+				resp, body := send(t, http.MethodPost, url, step.body, headers)
+				if resp.StatusCode != step.wantStatus {
+					t.Fatalf("step %d: status %d (%s), want %d", i, resp.StatusCode, body.Message, step.wantStatus)
+				}
+				if len(step.want) == 0 {
+					continue
+				}
+
+				var data map[string]json.RawMessage
+				if err := json.Unmarshal(body.Data, &data); err != nil {
+					t.Fatalf("step %d: decoding data: %v", i, err)
+				}
+				for field, want := range step.want {
+					got := string(data[field])
+					if got == "" {
+						got = "null"
+					}
+					if got != want {
+						t.Errorf("step %d: %s = %s, want %s", i, field, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEndSessionReport(t *testing.T) {
+	tests := []struct {
+		name string
+		// answers are given after the first question; nil ends the session
+		// before it is asked.
+		answers            []string
+		wantScore          float64
+		wantRecommendation string
+		wantTrajectory     int
+	}{
+		{
+			name:               "before the first question",
+			wantRecommendation: "insufficient-data",
+		},
+		{
+			name:               "before any answer",
+			answers:            []string{},
+			wantRecommendation: "insufficient-data",
+			wantTrajectory:     1,
+		},
+		{
+			name:               "after two rated answers",
+			answers:            []string{"Threads share memory.", "O(n)"},
+			wantScore:          7.5,
+			wantRecommendation: "lean-hire",
+			wantTrajectory:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, llm.NewScriptedProvider())
+			sessionId, guestToken := createGuestSession(t, server)
+			headers := map[string]string{controllers.GuestTokenHeader: guestToken}
+			ask := server.URL + "/api/v1/ask-to-gemini/" + sessionId
+
+			if tt.answers != nil {
+				if resp, body := send(t, http.MethodPost, ask, `{}`, headers); resp.StatusCode != http.StatusOK {
+					t.Fatalf("first question: status %d: %s", resp.StatusCode, body.Message)
+				}
+			}
+			for _, answer := range tt.answers {
+				payload, _ := json.Marshal(map[string]string{"answer": answer})
+				if resp, body := send(t, http.MethodPost, ask, string(payload), headers); resp.StatusCode != http.StatusOK {
+					t.Fatalf("answer %q: status %d: %s", answer, resp.StatusCode, body.Message)
+				}
+			}
+
+			resp, body := send(t, http.MethodPost, server.URL+"/api/v1/end/"+sessionId, ``, headers)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("ending session: status %d: %s", resp.StatusCode, body.Message)
+			}
+
+			var data struct {
+				Report struct {
+					OverallScore         float64           `json:"overallScore"`
+					Recommendation       string            `json:"recommendation"`
+					DifficultyTrajectory []json.RawMessage `json:"difficultyTrajectory"`
+				} `json:"report"`
+			}
+			if err := json.Unmarshal(body.Data, &data); err != nil {
+				t.Fatalf("decoding report: %v", err)
+			}
+			if data.Report.OverallScore != tt.wantScore || data.Report.Recommendation != tt.wantRecommendation {
+				t.Errorf("report scored %v with %q, want %v with %q",
+					data.Report.OverallScore, data.Report.Recommendation, tt.wantScore, tt.wantRecommendation)
+			}
+			if len(data.Report.DifficultyTrajectory) != tt.wantTrajectory {
+				t.Errorf("trajectory has %d turns, want %d", len(data.Report.DifficultyTrajectory), tt.wantTrajectory)
+			}
+		})
+	}
+}
+
+func TestEndSessionReportDefaultsLists(t *testing.T) {
+	// The model may leave out a list it has nothing for
+	provider := llm.NewScriptedProvider()
+	provider.ReportText = `{"turnTopics": [{"turn": 1, "topic": "Go"}], "strengths": ["Clear"],
+		"roadmap": ["Practice"], "recommendation": "hire", "summary": "Good."}`
+	server := newTestServer(t, provider)
+	sessionId, guestToken := createGuestSession(t, server)
+	headers := map[string]string{controllers.GuestTokenHeader: guestToken}
+
+	ask := server.URL + "/api/v1/ask-to-gemini/" + sessionId
+	for _, body := range []string{`{}`, `{"answer": "Threads share memory."}`} {
+		if resp, data := send(t, http.MethodPost, ask, body, headers); resp.StatusCode != http.StatusOK {
+			t.Fatalf("asking: status %d: %s", resp.StatusCode, data.Message)
+		}
+	}
+
+	resp, body := send(t, http.MethodPost, server.URL+"/api/v1/end/"+sessionId, ``, headers)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("ending session: status %d: %s", resp.StatusCode, body.Message)
+	}
+	var data struct {
+		Report map[string]json.RawMessage `json:"report"`
+	}
+	if err := json.Unmarshal(body.Data, &data); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+	for field, want := range map[string]string{"strengths": `["Clear"]`, "weaknesses": "[]", "roadmap": `["Practice"]`} {
+		if got := string(data.Report[field]); got != want {
+			t.Errorf("%s = %s, want %s", field, got, want)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/auth"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// APIKeyHeader carries an organization API key.
+	APIKeyHeader = "X-API-Key"
+	// AdminTokenHeader carries the deployment's ADMIN_TOKEN, which manages
+	// organizations.
+	AdminTokenHeader = "X-Admin-Token"
+)
+
+var (
+	orgStore store.OrgStore
+	// adminToken is empty when organization management is disabled.
+	adminToken string
+)
+
+// SetAdminToken sets the token that authorizes creating and listing
+// organizations. An empty token disables those routes.
+func SetAdminToken(token string) {
+	adminToken = token
+}
+
+type tenantKey struct{}
+
+// tenant is the organization a request acts for. org is nil for requests
+// outside any organization, whose orgId is primitive.NilObjectID.
+type tenant struct {
+	orgId primitive.ObjectID
+	org   *models.Organization
+	// viaAPIKey is set when the request carried the organization's API
+	// key rather than only a member's token, and scope is that key's scope.
+	viaAPIKey bool
+	scope     models.APIKeyScope
+}
+
+// config returns the organization's config, or the zero config outside any
+// organization.
+func (t tenant) config() models.OrgConfig {
+	if t.org == nil {
+		return models.OrgConfig{}
+	}
+	return t.org.Config
+}
+
+// requestTenant returns the tenant ResolveTenant found for r.
+func requestTenant(r *http.Request) tenant {
+	t, _ := r.Context().Value(tenantKey{}).(tenant)
+	return t
+}
+
+// ResolveTenant finds the organization of every request, from its API key
+// or from the organization of the authenticated user. It must run after
+// Authenticate. A token and an API key of different organizations are
+// rejected.
+func ResolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		var t tenant
+		if key := r.Header.Get(APIKeyHeader); key != "" {
+			org, err := orgStore.GetOrgByAPIKey(ctx, auth.HashAPIKey(key))
+			if err != nil {
+				if errors.Is(err, store.ErrNotFound) {
+					utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid API key")
+					return
+				}
+				log.Println("Failed to resolve API key:", err)
+				utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to resolve organization")
+				return
+			}
+			t = tenant{orgId: org.ID, org: org, viaAPIKey: true, scope: models.ScopeClient}
+			if apiKey := org.KeyByHash(auth.HashAPIKey(key)); apiKey != nil {
+				t.scope = apiKey.ScopeOrDefault()
+			}
+		}
+
+		if id, ok := authenticatedIdentity(r); ok {
+			if t.viaAPIKey && id.orgId != t.orgId {
+				utils.ErrorResponse(w, http.StatusForbidden, "Token and API key belong to different organizations")
+				return
+			}
+			if !t.viaAPIKey && !id.orgId.IsZero() {
+				org, err := orgStore.GetOrg(ctx, id.orgId)
+				if err != nil {
+					log.Println("Failed to resolve organization:", err)
+					utils.ErrorResponse(w, http.StatusUnauthorized, "Organization no longer exists")
+					return
+				}
+				t = tenant{orgId: org.ID, org: org}
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, t)))
+	})
+}
+
+// isAdmin reports whether r carries the deployment's admin token.
+func isAdmin(r *http.Request) bool {
+	token := r.Header.Get(AdminTokenHeader)
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// newAPIKey creates a named API key, returning the key itself and the
+// record to store.
+func newAPIKey(name string, scope models.APIKeyScope) (string, models.APIKey, error) {
+	key, id, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "default"
+	}
+	return key, models.APIKey{ID: id, Name: name, Scope: scope, Hash: hash, CreatedAt: time.Now()}, nil
+}
+
+// CreateOrg creates an organization together with its first admin and client
+// API keys. The keys are only returned here.
+func CreateOrg(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !isAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Admin token required")
+		return
+	}
+
+	var body struct {
+		Name   string           `json:"name"`
+		Config models.OrgConfig `json:"config"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if strings.TrimSpace(body.Name) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if err := body.Config.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	adminKey, adminAPIKey, err := newAPIKey("admin", models.ScopeAdmin)
+	if err != nil {
+		log.Println("Failed to create organization:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create organization")
+		return
+	}
+	clientKey, clientAPIKey, err := newAPIKey("client", models.ScopeClient)
+	if err != nil {
+		log.Println("Failed to create organization:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create organization")
+		return
+	}
+
+	now := time.Now()
+	org := &models.Organization{
+		Name:      strings.TrimSpace(body.Name),
+		Config:    body.Config,
+		APIKeys:   []models.APIKey{adminAPIKey, clientAPIKey},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := orgStore.CreateOrg(ctx, org); err != nil {
+		log.Println("Failed to create organization:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create organization")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Organization created successfully", map[string]interface{}{
+		"organization": org,
+		"adminKey":     adminKey,
+		"clientKey":    clientKey,
+	})
+}
+
+// ListOrgs lists every organization.
+func ListOrgs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !isAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Admin token required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	orgs, err := orgStore.ListOrgs(ctx)
+	if err != nil {
+		log.Println("Failed to list organizations:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list organizations")
+		return
+	}
+	utils.SuccessResponse(w, "Organizations retrieved successfully", orgs)
+}
+
+// managedOrg returns the organization r may administer, with an admin API
+// key or as one of its admins, answering 401 or 403 otherwise. Client keys,
+// which every member of the organization holds, are not enough.
+func managedOrg(w http.ResponseWriter, r *http.Request) (*models.Organization, bool) {
+	t := requestTenant(r)
+	if t.org == nil {
+		utils.ErrorResponse(w, http.StatusForbidden, "Organization admin key required")
+		return nil, false
+	}
+	if t.viaAPIKey && t.scope == models.ScopeAdmin {
+		return t.org, true
+	}
+
+	account, err := currentAccount(r)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusForbidden, "Organization admin key required")
+			return nil, false
+		}
+		log.Println("Failed to fetch user:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return nil, false
+	}
+	if account.RoleOrDefault() != models.RoleAdmin || account.OrgID != t.orgId {
+		utils.ErrorResponse(w, http.StatusForbidden, "Admin role required")
+		return nil, false
+	}
+	return t.org, true
+}
+
+// orgErrorResponse writes a failed organization update.
+func orgErrorResponse(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, store.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	log.Printf("%s: %v", message, err)
+	utils.ErrorResponse(w, http.StatusInternalServerError, message)
+}
+
+// GetOrg returns the organization r administers.
+func GetOrg(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	org, ok := managedOrg(w, r)
+	if !ok {
+		return
+	}
+	utils.SuccessResponse(w, "Organization retrieved successfully", org)
+}
+
+// UpdateOrgConfig replaces the organization's config. Sessions already
+// created keep the model and persona they started with.
+func UpdateOrgConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	org, ok := managedOrg(w, r)
+	if !ok {
+		return
+	}
+
+	var config models.OrgConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := config.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	updated, err := orgStore.UpdateOrgConfig(ctx, org.ID, config)
+	if err != nil {
+		orgErrorResponse(w, err, "Failed to update organization")
+		return
+	}
+	utils.SuccessResponse(w, "Organization updated successfully", updated)
+}
+
+// CreateAPIKey adds an API key to the organization. The key is only
+// returned here.
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	org, ok := managedOrg(w, r)
+	if !ok {
+		return
+	}
+	addAPIKey(w, r, org.ID)
+}
+
+// IssueOrgAPIKey adds an API key to any organization for the deployment
+// admin, e.g. an admin key for an organization whose admin keys were all
+// revoked.
+func IssueOrgAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !isAdmin(r) {
+		utils.ErrorResponse(w, http.StatusForbidden, "Admin token required")
+		return
+	}
+
+	orgId, err := primitive.ObjectIDFromHex(mux.Vars(r)["orgId"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+	addAPIKey(w, r, orgId)
+}
+
+// addAPIKey creates the key described by the request body, a name and a
+// scope defaulting to client, and adds it to the organization.
+func addAPIKey(w http.ResponseWriter, r *http.Request, orgId primitive.ObjectID) {
+	var body struct {
+		Name  string             `json:"name"`
+		Scope models.APIKeyScope `json:"scope"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+	if body.Scope == "" {
+		body.Scope = models.ScopeClient
+	}
+	if !body.Scope.IsValid() {
+		utils.ErrorResponse(w, http.StatusBadRequest, "scope should be one of 'client' or 'admin'")
+		return
+	}
+
+	key, apiKey, err := newAPIKey(body.Name, body.Scope)
+	if err != nil {
+		log.Println("Failed to create API key:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if _, err := orgStore.AddAPIKey(ctx, orgId, apiKey); err != nil {
+		orgErrorResponse(w, err, "Failed to create API key")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "API key created successfully", map[string]interface{}{
+		"id":     apiKey.ID,
+		"name":   apiKey.Name,
+		"scope":  apiKey.Scope,
+		"apiKey": key,
+	})
+}
+
+// RevokeAPIKey revokes one of the organization's API keys. Revoking the key
+// the request was made with is allowed.
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	org, ok := managedOrg(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	updated, err := orgStore.RevokeAPIKey(ctx, org.ID, mux.Vars(r)["keyId"], time.Now())
+	if err != nil {
+		orgErrorResponse(w, err, "Failed to revoke API key")
+		return
+	}
+	utils.SuccessResponse(w, "API key revoked successfully", updated)
+}
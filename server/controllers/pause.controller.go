@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PauseSession pauses an interview that is waiting for an answer. The
+// session clock stops until it is resumed: paused time does not count
+// towards inactivity or the session's maximum lifetime.
+func PauseSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	session, err := getOwnedSession(r, mux.Vars(r)["sessionId"])
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to get session")
+		return
+	}
+
+	paused, err := TransitionSession(session, models.Paused, bson.M{"pausedAt": time.Now()})
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to pause session")
+		return
+	}
+
+	utils.SuccessResponse(w, "Session paused successfully", paused)
+}
+
+// ResumeSession resumes a paused interview. The response carries a welcome
+// back message recapping the current question, in the same shape as
+// AskToGemini, and the candidate then answers that question as usual.
+func ResumeSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	extendWriteDeadline(w, llm.OpRecap, 1)
+
+	session, err := getOwnedSession(r, mux.Vars(r)["sessionId"])
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to get session")
+		return
+	}
+
+	if session.InterviewStatus != models.Paused {
+		if session.InterviewStatus.IsTerminal() {
+			sessionErrorResponse(w, &models.TransitionError{From: session.InterviewStatus, To: models.WaitingForAnswer}, "Failed to resume session")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusConflict, "Session is not paused")
+		return
+	}
+
+	questions, err := GetQuestion(session.OrgID, session.ID.Hex())
+	if err != nil || questions.CurrentTurn() == nil {
+		log.Printf("Error getting questions: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load interview history")
+		return
+	}
+
+	// Restart the clock, adding this pause to the total
+	now := time.Now()
+	pausedSeconds := session.PausedSeconds
+	if session.PausedAt != nil {
+		pausedSeconds += int64(now.Sub(*session.PausedAt).Seconds())
+	}
+	resumed, err := TransitionSession(session, models.WaitingForAnswer, bson.M{
+		"pausedAt":      nil,
+		"pausedSeconds": pausedSeconds,
+	})
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to resume session")
+		return
+	}
+
+	current := questions.CurrentTurn()
+	recap := welcomeBack(r.Context(), session, questions)
+
+	utils.SuccessResponse(w, "Session resumed successfully", map[string]interface{}{
+		"session":  resumed,
+		"question": recap.Question,
+		"code":     recap.Code,
+		"turn":     current.Number,
+	})
+}
+
+// welcomeBack asks the interviewer to welcome the candidate back and repeat
+// the current question. session must still be paused so PromptGenerator
+// builds the recap prompt. A plain message is used if the model fails, so a
+// candidate can always resume.
+func welcomeBack(ctx context.Context, session *models.Session, questions *models.Question) models.ExtractedResponse {
+	current := questions.CurrentTurn()
+	fallback := models.ExtractedResponse{
+		Question: "Welcome back! Let's pick up where we left off. " + current.Question,
+		Code:     current.Code,
+	}
+	if llmProvider == nil {
+		return fallback
+	}
+
+	prompt := utils.PromptGenerator(session, questions, "")
+	ctx = llm.WithSession(llm.WithOperation(ctx, llm.OpRecap), session.ID.Hex())
+	resp, err := llmProvider.GenerateText(ctx, prompt, llm.WithSchema(utils.ResponseSchema(true)), llm.WithModel(session.Model))
+	if err != nil {
+		log.Printf("Failed to generate welcome back message: %v", err)
+		return fallback
+	}
+	recordUsage(session.OrgID, session.ID, llm.OpRecap, resp)
+
+	recap, err := parseInterviewerResponse(resp.Text, false)
+	if err != nil {
+		log.Printf("Rejected welcome back message: %v", err)
+		return fallback
+	}
+	// The question being answered is unchanged, so neither is its code
+	recap.Code = current.Code
+	return recap
+}
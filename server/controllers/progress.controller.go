@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var progressStore store.ProgressStore
+
+// trendThreshold is how far, in rating points, an area's fitted score must
+// move across the window to count as improving or regressing.
+const trendThreshold = 0.5
+
+// progressFilter builds the filter of a progress request from the ?period=,
+// ?from= and ?to= query parameters. Progress is the caller's own unless a
+// reviewer asks for another user of their organization with ?userId=. It
+// writes an error response and returns false if the request is invalid.
+func progressFilter(w http.ResponseWriter, r *http.Request) (store.ProgressFilter, bool) {
+	var filter store.ProgressFilter
+
+	account, err := currentAccount(r)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Log in to see your progress")
+			return filter, false
+		}
+		log.Println("Failed to fetch user:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get progress")
+		return filter, false
+	}
+
+	query := r.URL.Query()
+	filter.OrgID = requestTenant(r).orgId
+	filter.UserID = account.ID
+	if userId := query.Get("userId"); userId != "" && userId != account.ID.Hex() {
+		if !account.RoleOrDefault().CanReview() {
+			utils.ErrorResponse(w, http.StatusForbidden, "Reviewer role required")
+			return filter, false
+		}
+		if filter.UserID, err = primitive.ObjectIDFromHex(userId); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "invalid userId")
+			return filter, false
+		}
+	}
+
+	if filter.Period, err = models.ParseProgressPeriod(query.Get("period")); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return filter, false
+	}
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = parseDateBound(from, false); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			return filter, false
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = parseDateBound(to, true); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			return filter, false
+		}
+	}
+	return filter, true
+}
+
+// loadProgress runs both aggregations, rounding their scores.
+func loadProgress(ctx context.Context, filter store.ProgressFilter) (stacks []models.ProgressPoint, topics []models.ProgressPoint, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if stacks, err = progressStore.StackProgress(ctx, filter); err != nil {
+		return nil, nil, err
+	}
+	if topics, err = progressStore.TopicProgress(ctx, filter); err != nil {
+		return nil, nil, err
+	}
+	for _, points := range [][]models.ProgressPoint{stacks, topics} {
+		for i := range points {
+			points[i].Score = roundScore(points[i].Score)
+		}
+	}
+	return stacks, topics, nil
+}
+
+// summarizeAreas splits points, sorted by area ignoring case and then by
+// period, into one series per area and classifies each series' trend.
+func summarizeAreas(points []models.ProgressPoint) []models.AreaProgress {
+	areas := []models.AreaProgress{}
+	for start := 0; start < len(points); {
+		end := start
+		for end < len(points) && strings.EqualFold(points[end].Area, points[start].Area) {
+			end++
+		}
+		areas = append(areas, summarizeArea(points[start:end]))
+		start = end
+	}
+	return areas
+}
+
+// summarizeArea fits a least-squares line through the scores of one area
+// over time. Change is how far the line moves between the first and the
+// latest period, so a single outlier does not flip the trend.
+func summarizeArea(points []models.ProgressPoint) models.AreaProgress {
+	area := models.AreaProgress{
+		Area:        points[len(points)-1].Area,
+		Points:      points,
+		LatestScore: points[len(points)-1].Score,
+		Trend:       models.NotEnoughData,
+	}
+	if len(points) < 2 {
+		return area
+	}
+
+	// x is the age of each point in days since the first one
+	first := points[0].Period
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(points))
+	for _, point := range points {
+		x := point.Period.Sub(first).Hours() / 24
+		sumX += x
+		sumY += point.Score
+		sumXY += x * point.Score
+		sumXX += x * x
+	}
+	span := points[len(points)-1].Period.Sub(first).Hours() / 24
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	area.Change = roundScore(slope * span)
+
+	switch {
+	case area.Change >= trendThreshold:
+		area.Trend = models.Improving
+	case area.Change <= -trendThreshold:
+		area.Trend = models.Regressing
+	default:
+		area.Trend = models.Steady
+	}
+	return area
+}
+
+// trendHighlight names an area that is improving or regressing.
+type trendHighlight struct {
+	Kind   string  `json:"kind"`
+	Area   string  `json:"area"`
+	Change float64 `json:"change"`
+}
+
+// highlights returns the areas with the given trend, largest change first.
+func highlights(trend models.ProgressTrend, groups map[string][]models.AreaProgress) []trendHighlight {
+	result := []trendHighlight{}
+	for kind, areas := range groups {
+		for _, area := range areas {
+			if area.Trend == trend {
+				result = append(result, trendHighlight{Kind: kind, Area: area.Area, Change: area.Change})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ci, cj := math.Abs(result[i].Change), math.Abs(result[j].Change)
+		if ci != cj {
+			return ci > cj
+		}
+		return result[i].Area < result[j].Area
+	})
+	return result
+}
+
+// GetProgress summarizes a user's ratings across sessions per tech stack and
+// per report topic, and highlights the areas that are improving or
+// regressing.
+func GetProgress(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := progressFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stackPoints, topicPoints, err := loadProgress(r.Context(), filter)
+	if err != nil {
+		log.Println("Failed to aggregate progress:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get progress")
+		return
+	}
+
+	groups := map[string][]models.AreaProgress{
+		"techStack": summarizeAreas(stackPoints),
+		"topic":     summarizeAreas(topicPoints),
+	}
+
+	utils.SuccessResponse(w, "Progress retrieved successfully", map[string]interface{}{
+		"userId":     filter.UserID.Hex(),
+		"period":     filter.Period,
+		"techStacks": groups["techStack"],
+		"topics":     groups["topic"],
+		"improving":  highlights(models.Improving, groups),
+		"regressing": highlights(models.Regressing, groups),
+	})
+}
+
+// GetProgressTimeSeries returns the raw per-period points of a user's
+// ratings by tech stack and by topic, sorted by area, ignoring case, and
+// period.
+func GetProgressTimeSeries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, ok := progressFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stackPoints, topicPoints, err := loadProgress(r.Context(), filter)
+	if err != nil {
+		log.Println("Failed to aggregate progress:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get progress")
+		return
+	}
+
+	utils.SuccessResponse(w, "Progress time series retrieved successfully", map[string]interface{}{
+		"userId":     filter.UserID.Hex(),
+		"period":     filter.Period,
+		"techStacks": stackPoints,
+		"topics":     topicPoints,
+	})
+}
@@ -2,34 +2,29 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
-	
-	"github.com/rnkp755/mockinterviewBackend/db"
 	"github.com/rnkp755/mockinterviewBackend/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/rnkp755/mockinterviewBackend/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var QuestionCollection *mongo.Collection
+var turnStore store.TurnStore
 
-func init() {
-	colName := os.Getenv("QUESTION_COLLECTION_NAME")
-	if colName == "" {
-		log.Println("Warning: QUESTION_COLLECTION_NAME not set. Question features will not work.")
-		return
+// requireEvaluating returns store.ErrConflict unless the session is still
+// evaluating a turn, so a turn finishing after the session was ended or
+// expired is not written to it.
+func requireEvaluating(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) error {
+	session, err := sessionStore.GetSession(ctx, orgId, sessionId)
+	if err != nil {
+		return err
 	}
-
-	QuestionCollection = db.ConnectToDb(colName)
-
-	if QuestionCollection == nil {
-		log.Println("Warning: Failed to initialize QuestionCollection")
+	if session.InterviewStatus != models.Evaluating {
+		return fmt.Errorf("%w: the session is %s and no longer takes answers", store.ErrConflict, session.InterviewStatus)
 	}
+	return nil
 }
 
 func AddQuestion(question models.Question) (*models.Question, error) {
@@ -37,15 +32,23 @@ func AddQuestion(question models.Question) (*models.Question, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := QuestionCollection.InsertOne(ctx, question)
-	if err != nil {
-		return nil, fmt.Errorf("failed to insert question document: %v", err)
+	if err := requireEvaluating(ctx, question.OrgID, question.SessionId); err != nil {
+		return nil, err
+	}
+	if err := turnStore.CreateQuestion(ctx, &question); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, fmt.Errorf("%w: the interview has already started", store.ErrConflict)
+		}
+		return nil, err
 	}
 
 	return &question, nil
 }
 
-func UpdateQuestion(questionText string, rating string, review string, sessionIdStr string) (*models.Question, error) {
+// UpdateQuestion stores the evaluation of the current turn and appends the
+// next question in a single atomic update. It refuses once the session has
+// left Evaluating.
+func UpdateQuestion(orgId primitive.ObjectID, sessionIdStr string, answered models.Turn, next models.Turn) (*models.Question, error) {
 	// 1. Validate Session ID
 	sessionId, err := primitive.ObjectIDFromHex(sessionIdStr)
 	if err != nil {
@@ -56,53 +59,25 @@ func UpdateQuestion(questionText string, rating string, review string, sessionId
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 3. Construct Update Document
-	// Always update the 'updatedAt' timestamp
-	updateDoc := bson.M{
-		"$set": bson.M{
-			"updatedAt": time.Now(),
-		},
-	}
-
-	// Construct the $push document dynamically based on inputs
-	pushFields := bson.M{}
-	if questionText != "" {
-		pushFields["question"] = questionText
-	}
-	if rating != "" {
-		pushFields["rating"] = rating
+	// 3. Execute Update
+	if err := requireEvaluating(ctx, orgId, sessionId); err != nil {
+		return nil, err
 	}
-	if review != "" {
-		// Maps 'review' argument to 'review' field (used for Feedback)
-		pushFields["review"] = review 
-	}
-
-	// Only add $push to the update if there are fields to push
-	if len(pushFields) > 0 {
-		updateDoc["$push"] = pushFields
-	}
-
-	// 4. Define Filter and Options
-	filter := bson.M{"sessionid": sessionId}
-	
-	// Return the document *after* the update is applied
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-
-	// 5. Execute Update
-	var updatedQuestion models.Question
-	err = QuestionCollection.FindOneAndUpdate(ctx, filter, updateDoc, opts).Decode(&updatedQuestion)
-	
+	updatedQuestion, err := turnStore.AppendTurn(ctx, orgId, sessionId, answered, next)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("question document not found for session %s", sessionIdStr)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no question history found for session %s", store.ErrNotFound, sessionIdStr)
+		}
+		if errors.Is(err, store.ErrConflict) {
+			return nil, fmt.Errorf("%w: turn %d has already been answered", store.ErrConflict, answered.Number)
 		}
-		return nil, fmt.Errorf("database error during update: %v", err)
+		return nil, err
 	}
 
-	return &updatedQuestion, nil
+	return updatedQuestion, nil
 }
 
-func GetQuestion(sessionIdStr string) (*models.Question, error) {
+func GetQuestion(orgId primitive.ObjectID, sessionIdStr string) (*models.Question, error) {
 	// 1. Validate Session ID
 	sessionId, err := primitive.ObjectIDFromHex(sessionIdStr)
 	if err != nil {
@@ -114,16 +89,13 @@ func GetQuestion(sessionIdStr string) (*models.Question, error) {
 	defer cancel()
 
 	// 3. Find Document
-	filter := bson.M{"sessionid": sessionId}
-	var question models.Question
-	
-	err = QuestionCollection.FindOne(ctx, filter).Decode(&question)
+	question, err := turnStore.GetQuestion(ctx, orgId, sessionId)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("no question history found for session %s", sessionIdStr)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no question history found for session %s", store.ErrNotFound, sessionIdStr)
 		}
-		return nil, fmt.Errorf("failed to fetch question: %v", err)
+		return nil, err
 	}
 
-	return &question, nil
-}
\ No newline at end of file
+	return question, nil
+}
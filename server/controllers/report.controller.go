@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+)
+
+// generalTopic groups the turns the model did not assign a topic to.
+const generalTopic = "General"
+
+// maxReportTimeout bounds generating and saving a report when the LLM report
+// timeout is disabled.
+const maxReportTimeout = 10 * time.Minute
+
+// reportTimeout bounds generating and saving a report, every re-prompt
+// included.
+func reportTimeout() time.Duration {
+	if budget := llmBudget(llm.OpReport, interviewAttempts()); budget > 0 {
+		return budget + writeDeadlineSlack
+	}
+	return maxReportTimeout
+}
+
+// generateReport writes the final report of an ended interview. The scores
+// come from the turn ratings; the model only contributes topics, feedback
+// and the recommendation.
+func generateReport(ctx context.Context, session *models.Session, questions *models.Question) (*models.Report, error) {
+	report := &models.Report{
+		Strengths:   []string{},
+		Weaknesses:  []string{},
+		Roadmap:     []string{},
+		GeneratedAt: time.Now(),
+	}
+	report.DifficultyTrajectory, report.FinalAbility = difficultyTrajectory(session, questions)
+
+	var rated []models.Turn
+	if questions != nil {
+		for _, turn := range questions.Turns {
+			if turn.Rating != nil {
+				rated = append(rated, turn)
+			}
+		}
+	}
+
+	// Nothing was evaluated, so there is nothing to ask the model about
+	if len(rated) == 0 {
+		report.TopicScores = []models.TopicScore{}
+		report.Recommendation = models.InsufficientData
+		report.Summary = "The interview ended before any answer was evaluated."
+		return report, nil
+	}
+	if llmProvider == nil {
+		return nil, fmt.Errorf("LLM provider not configured")
+	}
+
+	analysis, model, err := generateReportAnalysis(ctx, session, questions)
+	if err != nil {
+		return nil, err
+	}
+
+	report.OverallScore, report.TopicScores = scoreTurns(rated, analysis.TurnTopics)
+	report.Strengths = nonNil(analysis.Strengths)
+	report.Weaknesses = nonNil(analysis.Weaknesses)
+	report.Roadmap = nonNil(analysis.Roadmap)
+	report.Recommendation = analysis.Recommendation
+	report.Summary = analysis.Summary
+	report.Model = model
+	return report, nil
+}
+
+// generateReportAnalysis asks the model for the report, re-prompting after
+// each invalid reply like an interview turn.
+func generateReportAnalysis(ctx context.Context, session *models.Session, questions *models.Question) (*models.ReportAnalysis, string, error) {
+	maxAttempts := interviewAttempts()
+	basePrompt := utils.ReportPrompt(session, questions)
+	prompt := basePrompt
+
+	ctx = llm.WithSession(llm.WithOperation(ctx, llm.OpReport), session.ID.Hex())
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err := llmProvider.GenerateText(ctx, prompt, llm.WithSchema(utils.ReportSchema), llm.WithModel(session.Model))
+		if err != nil {
+			return nil, "", err
+		}
+		recordUsage(session.OrgID, session.ID, llm.OpReport, resp)
+
+		var analysis models.ReportAnalysis
+		err = json.Unmarshal([]byte(stripCodeFence(resp.Text)), &analysis)
+		if err == nil {
+			err = analysis.Validate()
+		}
+		if err == nil {
+			return &analysis, resp.Model, nil
+		}
+
+		log.Printf("Report attempt %d/%d rejected: %v", attempt, maxAttempts, err)
+		lastErr = err
+		prompt = utils.RepairPrompt(basePrompt, resp.Text, err)
+	}
+
+	return nil, "", fmt.Errorf("%w after %d attempts: %v", errMalformedResponse, maxAttempts, lastErr)
+}
+
+// difficultyTrajectory lists the level of every question asked, with the AI
+// rating of its answer and the ability estimate that rating led to. Turns
+// asked before difficulty was tracked are left out.
+func difficultyTrajectory(session *models.Session, questions *models.Question) ([]models.DifficultyPoint, float64) {
+	trajectory := []models.DifficultyPoint{}
+	if questions != nil {
+		for _, turn := range questions.Turns {
+			if turn.Difficulty == 0 {
+				continue
+			}
+			trajectory = append(trajectory, models.DifficultyPoint{
+				Turn:       turn.Number,
+				Difficulty: turn.Difficulty,
+				Rating:     turn.Rating,
+				Ability:    turn.Ability,
+			})
+		}
+	}
+	return trajectory, session.CurrentAbility()
+}
+
+// scoreTurns averages the ratings of the rated turns, overall and per topic.
+// A reviewer's rating replaces the AI one.
+// Topics are listed in the order they were first asked about.
+func scoreTurns(rated []models.Turn, turnTopics []models.TurnTopic) (float64, []models.TopicScore) {
+	topicOf := make(map[int]string, len(turnTopics))
+	for _, tt := range turnTopics {
+		if topic := strings.TrimSpace(tt.Topic); topic != "" {
+			topicOf[tt.Turn] = topic
+		}
+	}
+
+	var (
+		total  int
+		order  []string
+		sums   = map[string]int{}
+		counts = map[string]int{}
+	)
+	for _, turn := range rated {
+		topic, ok := topicOf[turn.Number]
+		if !ok {
+			topic = generalTopic
+		}
+		if counts[topic] == 0 {
+			order = append(order, topic)
+		}
+		rating := *turn.EffectiveRating()
+		sums[topic] += rating
+		counts[topic]++
+		total += rating
+	}
+
+	scores := make([]models.TopicScore, 0, len(order))
+	for _, topic := range order {
+		scores = append(scores, models.TopicScore{
+			Topic:     topic,
+			Score:     roundScore(float64(sums[topic]) / float64(counts[topic])),
+			Questions: counts[topic],
+		})
+	}
+	return roundScore(float64(total) / float64(len(rated))), scores
+}
+
+// roundScore rounds a score to one decimal place.
+func roundScore(score float64) float64 {
+	return math.Round(score*10) / 10
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
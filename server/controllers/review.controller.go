@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var noteStore store.NoteStore
+
+// getViewableSession is getOwnedSession for read-only routes, which
+// reviewers and admins may also use on any session of their organization.
+func getViewableSession(r *http.Request, sessionId string) (*models.Session, error) {
+	session, err := GetSession(requestTenant(r).orgId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if canAccessSession(r, session) {
+		return session, nil
+	}
+
+	account, err := currentAccount(r)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errSessionNotFound
+		}
+		return nil, err
+	}
+	if !account.RoleOrDefault().CanReview() {
+		return nil, errSessionNotFound
+	}
+	return session, nil
+}
+
+// reviewedSession returns the session a reviewer addresses in r, answering
+// the error response itself.
+func reviewedSession(w http.ResponseWriter, r *http.Request, message string) (*models.UserAccount, *models.Session, bool) {
+	reviewer, ok := requireReviewer(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	session, err := GetSession(requestTenant(r).orgId, mux.Vars(r)["sessionId"])
+	if err != nil {
+		sessionErrorResponse(w, err, message)
+		return nil, nil, false
+	}
+	return reviewer, session, true
+}
+
+// GetSessionNotes returns the reviewer notes on a session.
+func GetSessionNotes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, session, ok := reviewedSession(w, r, "Failed to get notes")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	notes, err := noteStore.ListNotes(ctx, session.OrgID, session.ID)
+	if err != nil {
+		log.Println("Failed to fetch notes:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get notes")
+		return
+	}
+
+	utils.SuccessResponse(w, "Notes retrieved successfully", map[string]interface{}{
+		"sessionId": session.ID.Hex(),
+		"notes":     notes,
+	})
+}
+
+// AddSessionNote leaves a reviewer note on a session. Notes can be added in
+// any state, including after the interview has ended.
+func AddSessionNote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	reviewer, session, ok := reviewedSession(w, r, "Failed to add note")
+	if !ok {
+		return
+	}
+
+	var note models.ReviewNote
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := note.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	note.ID = primitive.NilObjectID
+	note.OrgID = session.OrgID
+	note.SessionID = session.ID
+	note.AuthorID = reviewer.ID
+	note.AuthorName = reviewer.Name
+	note.CreatedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := noteStore.AddNote(ctx, &note); err != nil {
+		log.Println("Failed to add note:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to add note")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, "Note added successfully", note)
+}
+
+// ReviewTurn overrides the AI rating, and optionally the feedback, of a
+// rated turn. The AI evaluation is kept next to the override so the two can
+// be compared, and every override is recorded in the audit trail.
+func ReviewTurn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	reviewer, session, ok := reviewedSession(w, r, "Failed to review turn")
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(mux.Vars(r)["turn"])
+	if err != nil || number < 1 {
+		utils.ErrorResponse(w, http.StatusNotFound, "Turn not found")
+		return
+	}
+
+	var review models.TurnReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := review.Validate(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	review.ReviewerID = reviewer.ID
+	review.ReviewerName = reviewer.Name
+	review.ReviewedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	questions, err := turnStore.ReviewTurn(ctx, session.OrgID, session.ID, number, review)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.ErrorResponse(w, http.StatusNotFound, "Turn not found")
+		case errors.Is(err, store.ErrConflict):
+			utils.ErrorResponse(w, http.StatusConflict, "Turn has not been rated yet")
+		default:
+			log.Println("Failed to review turn:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to review turn")
+		}
+		return
+	}
+
+	turn := questions.Turns[number-1]
+	recordAuditEvent(&models.AuditEvent{
+		OrgID:     session.OrgID,
+		Action:    models.AuditTurnReviewed,
+		ActorID:   reviewer.ID,
+		SessionID: session.ID,
+		Details: map[string]string{
+			"turn":     strconv.Itoa(number),
+			"aiRating": strconv.Itoa(*turn.Rating),
+			"rating":   strconv.Itoa(review.Rating),
+		},
+	})
+
+	utils.SuccessResponse(w, "Turn reviewed successfully", turn)
+}
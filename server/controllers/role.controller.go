@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// currentAccount loads the account of the authenticated caller of r. Roles
+// are read on every request rather than from the token, so a role change
+// applies immediately. Guests get store.ErrNotFound.
+func currentAccount(r *http.Request) (*models.UserAccount, error) {
+	userId, ok := authenticatedUser(r)
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	return userStore.GetUser(ctx, userId)
+}
+
+// requireReviewer returns the caller's account if they may review the
+// organization's sessions, answering 401 or 403 otherwise.
+func requireReviewer(w http.ResponseWriter, r *http.Request) (*models.UserAccount, bool) {
+	account, err := currentAccount(r)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+			return nil, false
+		}
+		log.Println("Failed to fetch user:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return nil, false
+	}
+	if !account.RoleOrDefault().CanReview() {
+		utils.ErrorResponse(w, http.StatusForbidden, "Reviewer role required")
+		return nil, false
+	}
+	return account, true
+}
+
+// canManageRoles reports whether r may list the organization's accounts and
+// change their roles: an admin of the organization, a holder of its admin
+// API key or the deployment admin. actor is the admin's account, if any.
+func canManageRoles(w http.ResponseWriter, r *http.Request) (actor *models.UserAccount, ok bool) {
+	if t := requestTenant(r); isAdmin(r) || (t.viaAPIKey && t.scope == models.ScopeAdmin) {
+		return nil, true
+	}
+
+	account, err := currentAccount(r)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+			return nil, false
+		}
+		log.Println("Failed to fetch user:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return nil, false
+	}
+	if account.RoleOrDefault() != models.RoleAdmin {
+		utils.ErrorResponse(w, http.StatusForbidden, "Admin role required")
+		return nil, false
+	}
+	return account, true
+}
+
+// ListUsers lists the accounts of the request's organization.
+func ListUsers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := canManageRoles(w, r); !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	users, err := userStore.ListUsers(ctx, requestTenant(r).orgId)
+	if err != nil {
+		log.Println("Failed to list users:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+	utils.SuccessResponse(w, "Users retrieved successfully", users)
+}
+
+// SetUserRole changes the role of an account of the request's organization.
+// Admins cannot change their own role, so an organization always keeps the
+// admin who made the change.
+func SetUserRole(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	actor, ok := canManageRoles(w, r)
+	if !ok {
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(mux.Vars(r)["userId"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	var body struct {
+		Role models.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if !body.Role.IsValid() {
+		utils.ErrorResponse(w, http.StatusBadRequest, "role must be one of candidate, reviewer or admin")
+		return
+	}
+	if actor != nil && actor.ID == userId {
+		utils.ErrorResponse(w, http.StatusForbidden, "Admins cannot change their own role")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	user, err := userStore.SetUserRole(ctx, requestTenant(r).orgId, userId, body.Role)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Println("Failed to update role:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update role")
+		return
+	}
+	utils.SuccessResponse(w, "Role updated successfully", user)
+}
@@ -2,52 +2,77 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 
-	"github.com/rnkp755/mockinterviewBackend/db"
 	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/auth"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+	"github.com/rnkp755/mockinterviewBackend/store"
 	"github.com/rnkp755/mockinterviewBackend/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var SessionCollection *mongo.Collection
-
-func init() {
-
-	colName := os.Getenv("SESSION_COLLECTION_NAME")
-	if colName == "" {
-		log.Println("Warning: SESSION_COLLECTION_NAME not set. Session features may not work.")
-		return
-	}
-
-	SessionCollection = db.ConnectToDb(colName)
-
-	if SessionCollection == nil {
-		log.Println("Warning: Failed to initialize SessionCollection")
-	}
+var sessionStore store.SessionStore
+
+var errSessionNotFound = errors.New("session not found")
+
+// GuestTokenHeader carries the capability token of a guest session. It is
+// returned once by CreateSession and must be sent with every request for
+// that session.
+const GuestTokenHeader = "X-Guest-Token"
+
+// SetStores sets the persistence used by every controller.
+func SetStores(stores *store.Stores) {
+	sessionStore = stores.Sessions
+	turnStore = stores.Turns
+	userStore = stores.Users
+	auditStore = stores.Audit
+	orgStore = stores.Orgs
+	noteStore = stores.Notes
+	progressStore = stores.Progress
+	usageStore = stores.Usage
 }
 
 func createNewSession(session models.Session) (primitive.ObjectID, error) {
-	fmt.Println("Creating new session ...", SessionCollection)
-	result, err := SessionCollection.InsertOne(context.TODO(), session)
-	if err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := sessionStore.CreateSession(ctx, &session); err != nil {
 		log.Println("Failed to insert session: ", err)
 		return primitive.NilObjectID, err
 	}
 
-	sessionId := result.InsertedID.(primitive.ObjectID)
+	return session.ID, nil
+}
 
-	return sessionId, nil
+// sessionQuotaExceeded reports whether the tenant orgId has already created
+// max sessions since the start of the UTC day.
+func sessionQuotaExceeded(ctx context.Context, orgId primitive.ObjectID, max int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	sessions, err := sessionStore.ListSessions(ctx, store.SessionFilter{
+		OrgID:        orgId,
+		CreatedAfter: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Limit:        max,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(sessions) >= max, nil
 }
 
 func CreateSession(w http.ResponseWriter, r *http.Request) {
@@ -61,11 +86,62 @@ func CreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The owner comes from the token, never from the body
+	if userId, ok := authenticatedUser(r); ok {
+		session.UserType = models.User
+		session.UserID = userId
+	} else if session.UserType == models.User {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Log in to create a user session")
+		return
+	} else {
+		session.UserID = primitive.NilObjectID
+	}
+
 	if err := session.ValidateAndInitialize(); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
+	// The session belongs to the request's organization and keeps the
+	// model and persona it was configured with at creation
+	tenant := requestTenant(r)
+	config := tenant.config()
+	session.OrgID = tenant.orgId
+	session.Model = config.Model
+	session.Persona = config.Persona
+
+	for _, stack := range session.TechStacks {
+		if !config.AllowsTechStack(stack) {
+			utils.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Tech stack %q is not allowed by your organization", stack))
+			return
+		}
+	}
+
+	if config.MaxSessionsPerDay > 0 {
+		exceeded, err := sessionQuotaExceeded(r.Context(), tenant.orgId, config.MaxSessionsPerDay)
+		if err != nil {
+			log.Println("Failed to check session quota:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create session")
+			return
+		}
+		if exceeded {
+			utils.ErrorResponse(w, http.StatusTooManyRequests, "Daily session limit reached, please try again tomorrow")
+			return
+		}
+	}
+
+	// Only the holder of the guest token can use a guest session
+	var guestToken string
+	if session.UserType == models.Guest {
+		var err error
+		guestToken, session.GuestTokenHash, err = auth.NewGuestToken()
+		if err != nil {
+			log.Println("Failed to create session:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create session")
+			return
+		}
+	}
+
 	sessionId, err := createNewSession(session)
 
 	if err != nil {
@@ -89,101 +165,407 @@ func CreateSession(w http.ResponseWriter, r *http.Request) {
 		http.SetCookie(w, cookie)
 	*/
 
+	if guestToken != "" {
+		w.Header().Set(GuestTokenHeader, guestToken)
+	}
 	utils.SuccessResponse(w, "Session created successfully", sessionId.Hex())
 }
 
-func GetSession(sessionId string) (*models.Session, error) {
+// GetSession fetches a session of the tenant orgId. Sessions of other
+// tenants are reported as not found.
+func GetSession(orgId primitive.ObjectID, sessionId string) (*models.Session, error) {
 	objectId, err := primitive.ObjectIDFromHex(sessionId)
 	if err != nil {
 		return nil, fmt.Errorf("invalid session ID: %v", err)
 	}
 
-	var session models.Session
-	err = SessionCollection.FindOne(context.TODO(), bson.M{"_id": objectId}).Decode(&session)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := sessionStore.GetSession(ctx, orgId, objectId)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("session not found")
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errSessionNotFound
 		}
-		return nil, fmt.Errorf("failed to fetch session: %v", err)
+		return nil, err
 	}
 
-	return &session, nil
+	// Don't wait for the sweeper to expire a stale session
+	expired, err := expireIfStale(session)
+	if err != nil {
+		log.Printf("Failed to expire stale session %s: %v", sessionId, err)
+		return session, nil
+	}
+
+	return expired, nil
+}
+
+// canAccessSession reports whether the caller of r owns session: the user
+// it belongs to, or for a guest session the holder of its guest token.
+func canAccessSession(r *http.Request, session *models.Session) bool {
+	if session.UserType == models.User {
+		userId, ok := authenticatedUser(r)
+		return ok && !session.UserID.IsZero() && userId == session.UserID
+	}
+	return auth.CheckGuestToken(session.GuestTokenHash, r.Header.Get(GuestTokenHeader))
 }
 
-func UpdateSession(sessionId string, updateFields bson.M) (*models.Session, error) {
+// getOwnedSession is GetSession for the caller of r. Sessions the caller
+// does not own are reported as not found, so their IDs cannot be probed.
+func getOwnedSession(r *http.Request, sessionId string) (*models.Session, error) {
+	session, err := GetSession(requestTenant(r).orgId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccessSession(r, session) {
+		return nil, errSessionNotFound
+	}
+	return session, nil
+}
+
+// UpdateSession sets fields other than the interview status, which only
+// TransitionSession may change.
+func UpdateSession(orgId primitive.ObjectID, sessionId string, updateFields bson.M) (*models.Session, error) {
 	objectId, err := primitive.ObjectIDFromHex(sessionId)
 	if err != nil {
 		return nil, fmt.Errorf("invalid session ID: %v", err)
 	}
 
+	if _, ok := updateFields["interviewstatus"]; ok {
+		return nil, fmt.Errorf("interview status must be changed with TransitionSession")
+	}
+
 	// Fetch session details from the database
-	session, err := GetSession(sessionId)
+	session, err := GetSession(orgId, sessionId)
 
 	if err != nil {
-		return nil, fmt.Errorf("session not found")
+		return nil, err
 	}
 
-	if session.InterviewStatus == models.Ended {
+	if session.InterviewStatus.IsTerminal() {
 		return session, nil
 	} else {
 
 		// Add UpdatedAt field to the updateFields
 		updateFields["updatedAt"] = time.Now()
 
-		update := bson.M{
-			"$set": updateFields,
-		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
 		// Perform the update
-		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-		var updatedSession models.Session
-		err = SessionCollection.FindOneAndUpdate(context.TODO(), bson.M{"_id": objectId}, update, opts).Decode(&updatedSession)
+		updatedSession, err := sessionStore.UpdateSession(ctx, orgId, objectId, updateFields)
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return nil, fmt.Errorf("session not found")
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, errSessionNotFound
 			}
-			return nil, fmt.Errorf("failed to update session: %v", err)
+			return nil, err
 		}
 
-		return &updatedSession, nil
+		return updatedSession, nil
+	}
+}
+
+// TransitionSession moves session to the status to, setting fields in the
+// same write. The move must be allowed by the interview state machine and
+// only succeeds if the stored status is still session.InterviewStatus, so
+// concurrent requests cannot both win. It is the only way to change status.
+func TransitionSession(session *models.Session, to models.AllowedInterviewStatus, fields bson.M) (*models.Session, error) {
+	if err := session.InterviewStatus.Transition(to); err != nil {
+		return nil, err
+	}
+
+	if fields == nil {
+		fields = bson.M{}
+	}
+	fields["updatedAt"] = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updatedSession, err := sessionStore.TransitionSession(ctx, session.OrgID, session.ID, session.InterviewStatus, to, fields)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errSessionNotFound
+		}
+		if errors.Is(err, store.ErrConflict) {
+			return nil, fmt.Errorf("%w: session is no longer %s", store.ErrConflict, session.InterviewStatus)
+		}
+		return nil, err
+	}
+
+	return updatedSession, nil
+}
+
+// sessionErrorResponse writes err as the matching status code, falling back
+// to a 500 with message. Expired sessions get a 410 so clients can tell them
+// apart from other illegal transitions.
+func sessionErrorResponse(w http.ResponseWriter, err error, message string) {
+	var transitionErr *models.TransitionError
+	switch {
+	case errors.As(err, &transitionErr) && transitionErr.From == models.Expired:
+		utils.ErrorResponse(w, http.StatusGone, "Session has expired")
+	case errors.Is(err, errSessionNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, "Session not found")
+	case errors.Is(err, models.ErrIllegalTransition), errors.Is(err, store.ErrConflict):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusInternalServerError, message)
 	}
 }
 
 func EndSession(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Allow-Control-Allow-Methods", "POST")
+	extendWriteDeadline(w, llm.OpReport, interviewAttempts())
 
 	// Extract sessionId from URL like /session/{sessionId}
 	vars := mux.Vars(r)
 	sessionId := vars["sessionId"]
 
-	// Add logic for ending session
-	updatedSession, err := UpdateSession(sessionId,
-		bson.M{
-			"interviewstatus": "ended",
-		},
-	)
-
+	updatedSession, err := getOwnedSession(r, sessionId)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to end session")
+		sessionErrorResponse(w, err, "Failed to end session")
 		return
 	}
 
-	var questions *models.Question
-	questions, err = GetQuestion(updatedSession.ID.Hex())
+	// A turn being evaluated would be missing from the report, and its
+	// evaluation could not be saved once the session is ended
+	if updatedSession.InterviewStatus == models.Evaluating {
+		utils.ErrorResponse(w, http.StatusConflict, "An answer is still being evaluated, try again shortly")
+		return
+	}
 
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate report")
+	// Load the turns before ending the session, so a failure leaves it
+	// running. Sessions ended before their first question have none. No
+	// turn is written meanwhile: that needs the session to be evaluating,
+	// which the transition below rules out.
+	questions, err := GetQuestion(updatedSession.OrgID, updatedSession.ID.Hex())
+	if errors.Is(err, store.ErrNotFound) {
+		questions = &models.Question{SessionId: updatedSession.ID, OrgID: updatedSession.OrgID, Turns: []models.Turn{}}
+	} else if err != nil {
+		log.Printf("Failed to fetch turns of session %s: %v", sessionId, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to end session")
 		return
 	}
 
-	// Print the report
-	fmt.Println(questions)
+	// Ending an ended or expired session just returns the report again
+	if !updatedSession.InterviewStatus.IsTerminal() {
+		updatedSession, err = TransitionSession(updatedSession, models.Ended, nil)
+		if err != nil {
+			sessionErrorResponse(w, err, "Failed to end session")
+			return
+		}
+	}
+
+	// The report is generated once; later calls return the stored one. If
+	// generating it fails the session is still ended and the next call
+	// tries again. The session is already ended, so the report is generated
+	// and saved even if the client goes away meanwhile.
+	if updatedSession.Report == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), reportTimeout())
+		defer cancel()
+
+		report, err := generateReport(ctx, updatedSession, questions)
+		if err != nil {
+			log.Printf("Failed to generate report for session %s: %v", sessionId, err)
+		} else if saved, err := sessionStore.SaveReport(ctx, updatedSession.OrgID, updatedSession.ID, report); err != nil {
+			log.Printf("Failed to save report for session %s: %v", sessionId, err)
+			updatedSession.Report = report
+		} else {
+			updatedSession = saved
+		}
+	}
 
 	response := map[string]interface{}{
 		"session":   updatedSession,
 		"questions": questions,
+		"report":    updatedSession.Report,
 	}
 
 	utils.SuccessResponse(w, "Session ended successfully", response)
 }
+
+// GetSessionDetails returns a session together with its turns. Reviewers
+// may read any session of their organization.
+func GetSessionDetails(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	session, err := getViewableSession(r, mux.Vars(r)["sessionId"])
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to get session")
+		return
+	}
+
+	// Sessions that have not started yet have no question document
+	turns := []models.Turn{}
+	if questions, err := GetQuestion(session.OrgID, session.ID.Hex()); err == nil {
+		turns = questions.Turns
+	}
+
+	utils.SuccessResponse(w, "Session retrieved successfully", map[string]interface{}{
+		"session": session,
+		"turns":   turns,
+	})
+}
+
+const (
+	defaultSessionPageSize = 20
+	maxSessionPageSize     = 100
+)
+
+// encodeSessionCursor returns an opaque cursor pointing after session.
+func encodeSessionCursor(session *models.Session) string {
+	raw := fmt.Sprintf("%d:%s", session.CreatedAt.UnixMilli(), session.ID.Hex())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSessionCursor(cursor string) (*store.SessionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	millis, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &store.SessionCursor{CreatedAt: time.UnixMilli(ms).UTC(), ID: objectId}, nil
+}
+
+// parseDateBound accepts an RFC 3339 timestamp or a YYYY-MM-DD date. A date
+// used as an upper bound includes the whole day.
+func parseDateBound(value string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// sessionFilterFromQuery builds a listing filter from the ?status= (comma
+// separated), ?techStack=, ?from=, ?to=, ?limit= and ?cursor= query
+// parameters.
+func sessionFilterFromQuery(query url.Values) (store.SessionFilter, error) {
+	filter := store.SessionFilter{
+		TechStack: strings.TrimSpace(query.Get("techStack")),
+		Limit:     defaultSessionPageSize,
+	}
+
+	if statuses := query.Get("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			status := models.AllowedInterviewStatus(strings.TrimSpace(status))
+			if !status.IsValid() {
+				return filter, fmt.Errorf("invalid status %q", status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseDateBound(from, false)
+		if err != nil {
+			return filter, fmt.Errorf("from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+		filter.CreatedAfter = t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := parseDateBound(to, true)
+		if err != nil {
+			return filter, fmt.Errorf("to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+		filter.CreatedBefore = t
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxSessionPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxSessionPageSize)
+		}
+		filter.Limit = n
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := decodeSessionCursor(cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = after
+	}
+
+	return filter, nil
+}
+
+// ListSessions returns a page of the caller's sessions, newest first. The
+// response's nextCursor is passed back as ?cursor= to fetch the next page and
+// is empty on the last page. Reviewers and admins list every session of
+// their organization instead, optionally narrowed to one user with
+// ?userId=.
+func ListSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	account, err := currentAccount(r)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Log in to list sessions")
+			return
+		}
+		log.Println("Failed to fetch user:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	filter, err := sessionFilterFromQuery(r.URL.Query())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.OrgID = requestTenant(r).orgId
+	filter.UserID = account.ID
+	if account.RoleOrDefault().CanReview() {
+		filter.UserID = primitive.NilObjectID
+		if userId := r.URL.Query().Get("userId"); userId != "" {
+			if filter.UserID, err = primitive.ObjectIDFromHex(userId); err != nil {
+				utils.ErrorResponse(w, http.StatusBadRequest, "invalid userId")
+				return
+			}
+		}
+	}
+
+	// Ask for one extra session to learn whether there is another page
+	pageSize := filter.Limit
+	filter.Limit++
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	sessions, err := sessionStore.ListSessions(ctx, filter)
+	if err != nil {
+		log.Println("Failed to list sessions:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	nextCursor := ""
+	if len(sessions) > pageSize {
+		sessions = sessions[:pageSize]
+		nextCursor = encodeSessionCursor(&sessions[pageSize-1])
+	}
+
+	utils.SuccessResponse(w, "Sessions retrieved successfully", map[string]interface{}{
+		"sessions":   sessions,
+		"nextCursor": nextCursor,
+	})
+}
@@ -0,0 +1,67 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rnkp755/mockinterviewBackend/controllers"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+)
+
+// heldProvider holds every text call until release is closed, announcing it
+// on started.
+type heldProvider struct {
+	*llm.ScriptedProvider
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *heldProvider) GenerateText(ctx context.Context, prompt string, opts ...llm.Option) (*llm.Response, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return p.ScriptedProvider.GenerateText(ctx, prompt, opts...)
+}
+
+func TestEndSessionWhileEvaluating(t *testing.T) {
+	provider := &heldProvider{
+		ScriptedProvider: llm.NewScriptedProvider(),
+		started:          make(chan struct{}, 1),
+		release:          make(chan struct{}),
+	}
+	server := newTestServer(t, provider)
+	sessionId, guestToken := createGuestSession(t, server)
+	headers := map[string]string{controllers.GuestTokenHeader: guestToken}
+
+	// send may not fail the test from another goroutine
+	asked := make(chan int)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/ask-to-gemini/"+sessionId, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(controllers.GuestTokenHeader, guestToken)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			asked <- 0
+			return
+		}
+		resp.Body.Close()
+		asked <- resp.StatusCode
+	}()
+	<-provider.started
+
+	resp, body := send(t, http.MethodPost, server.URL+"/api/v1/end/"+sessionId, ``, headers)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("ending while evaluating: status %d (%s), want %d", resp.StatusCode, body.Message, http.StatusConflict)
+	}
+
+	close(provider.release)
+	if status := <-asked; status != http.StatusOK {
+		t.Fatalf("first question: status %d, want %d", status, http.StatusOK)
+	}
+
+	resp, body = send(t, http.MethodPost, server.URL+"/api/v1/end/"+sessionId, ``, headers)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ending after the evaluation: status %d (%s), want %d", resp.StatusCode, body.Message, http.StatusOK)
+	}
+}
@@ -6,34 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
-	"time"
 
-	"github.com/google/generative-ai-go/genai"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
 	"github.com/rnkp755/mockinterviewBackend/utils"
-	"google.golang.org/api/option"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var resumeClient *genai.Client
-var resumeModel *genai.GenerativeModel
-
-func init() {
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		return
-	}
-
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return
-	}
-
-	resumeClient = client
-	resumeModel = client.GenerativeModel("gemini-2.5-flash")
-}
-
 func UploadResume(w http.ResponseWriter, r *http.Request) {
 
 	// Handle preflight
@@ -46,6 +25,7 @@ func UploadResume(w http.ResponseWriter, r *http.Request) {
 		utils.ErrorResponse(w, http.StatusMethodNotAllowed, "Invalid request method")
 		return
 	}
+	extendWriteDeadline(w, llm.OpResume, 1)
 
 	// Parse multipart form (max 10MB)
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
@@ -74,23 +54,51 @@ func UploadResume(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse with Gemini
-	resumeData, err := parseResumeWithGemini(fileBytes)
+	// Usage is attributed to a session when the client already has one. It
+	// must be the caller's own, or anyone could run up another candidate's
+	// budget until their interview is ended.
+	tenant := requestTenant(r)
+	sessionId := primitive.NilObjectID
+	model := tenant.config().Model
+	if id := r.FormValue("sessionId"); id != "" {
+		if !primitive.IsValidObjectID(id) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+			return
+		}
+		session, err := getOwnedSession(r, id)
+		if err != nil {
+			sessionErrorResponse(w, err, "Failed to parse resume")
+			return
+		}
+		if err := checkBudgets(session, tenant.config()); err != nil {
+			utils.ErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		sessionId = session.ID
+		model = session.Model
+	} else if err := checkDailyBudgets(tenant.orgId, tenant.config()); err != nil {
+		utils.ErrorResponse(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	resumeData, err := parseResumeWithGemini(r.Context(), tenant.orgId, sessionId, model, fileBytes)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		llmErrorResponse(w, err)
 		return
 	}
 
 	utils.SuccessResponse(w, "Resume parsed successfully", resumeData)
 }
 
-func parseResumeWithGemini(fileBytes []byte) (map[string]interface{}, error) {
+// parseResumeWithGemini extracts the resume's details with model, or the
+// provider's default model when it is empty.
+func parseResumeWithGemini(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, model string, fileBytes []byte) (map[string]interface{}, error) {
 
-	if resumeModel == nil {
-		return nil, fmt.Errorf("Gemini not initialized")
+	if llmProvider == nil {
+		return nil, fmt.Errorf("LLM provider not initialized")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	ctx = llm.WithSession(llm.WithOperation(ctx, llm.OpResume), sessionId.Hex())
 
 	prompt := `Extract information from this resume PDF and return ONLY a valid JSON object:
 
@@ -109,35 +117,25 @@ func parseResumeWithGemini(fileBytes []byte) (map[string]interface{}, error) {
 
 Return only the JSON. No markdown formatting.`
 
-	resp, err := resumeModel.GenerateContent(
+	resp, err := llmProvider.GenerateWithBlobs(
 		ctx,
-		genai.Text(prompt),
-		genai.Blob{
+		prompt,
+		[]llm.Blob{{
 			MIMEType: "application/pdf",
 			Data:     fileBytes,
-		},
+		}},
+		llm.WithModel(model),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini request failed: %v", err)
+		return nil, err
 	}
 
-	if len(resp.Candidates) == 0 ||
-		len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
-	}
-
-	textPart, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
-	if !ok {
-		return nil, fmt.Errorf("unexpected Gemini response format")
-	}
+	recordUsage(orgId, sessionId, llm.OpResume, resp)
 
-	responseText := string(textPart)
+	responseText := resp.Text
 
 	// Clean markdown formatting if present
-	responseText = strings.TrimPrefix(responseText, "```json")
-	responseText = strings.TrimPrefix(responseText, "```")
-	responseText = strings.TrimSuffix(responseText, "```")
-	responseText = strings.TrimSpace(responseText)
+	responseText = stripCodeFence(responseText)
 
 	var result map[string]interface{}
 	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
@@ -145,4 +143,4 @@ Return only the JSON. No markdown formatting.`
 	}
 
 	return result, nil
-}
\ No newline at end of file
+}
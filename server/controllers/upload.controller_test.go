@@ -0,0 +1,82 @@
+package controllers_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/rnkp755/mockinterviewBackend/controllers"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+)
+
+// uploadResume posts a resume, attributed to sessionId when it is not empty.
+func uploadResume(t *testing.T, url string, sessionId string, headers map[string]string) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "resume.pdf")
+	if err != nil {
+		t.Fatalf("building form: %v", err)
+	}
+	part.Write([]byte("%PDF-1.4"))
+	if sessionId != "" {
+		form.WriteField("sessionId", sessionId)
+	}
+	form.Close()
+
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("uploading resume: %v", err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestUploadResumeSessionOwnership(t *testing.T) {
+	server := newTestServer(t, llm.NewScriptedProvider())
+	ownSession, ownToken := createGuestSession(t, server)
+	otherSession, _ := createGuestSession(t, server)
+	headers := map[string]string{controllers.GuestTokenHeader: ownToken}
+
+	tests := []struct {
+		name       string
+		sessionId  string
+		wantStatus int
+	}{
+		{"without a session", "", http.StatusOK},
+		{"own session", ownSession, http.StatusOK},
+		{"another candidate's session", otherSession, http.StatusNotFound},
+		{"malformed session ID", "not-an-id", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := uploadResume(t, server.URL+"/api/v1/upload", tt.sessionId, headers)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestUploadResumeDailyBudget(t *testing.T) {
+	t.Setenv("DAILY_TOKEN_BUDGET", "1")
+	server := newTestServer(t, llm.NewScriptedProvider())
+
+	if resp := uploadResume(t, server.URL+"/api/v1/upload", "", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("first upload: status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp := uploadResume(t, server.URL+"/api/v1/upload", "", nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("upload over the daily budget: status %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+}
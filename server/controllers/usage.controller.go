@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+	"github.com/rnkp755/mockinterviewBackend/store"
+	"github.com/rnkp755/mockinterviewBackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// usageStore is nil when usage records are not persisted.
+var usageStore store.UsageStore
+
+// tokenBudget reads a token ceiling from the environment. Zero means unlimited.
+func tokenBudget(key string) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// recordUsage stores the usage of one LLM call by the tenant orgId and adds
+// it to the session's running total. sessionId may be primitive.NilObjectID.
+func recordUsage(orgId primitive.ObjectID, sessionId primitive.ObjectID, op llm.Operation, resp *llm.Response) {
+	if resp == nil {
+		return
+	}
+
+	cost := llm.PricingFor(resp.Model).Cost(resp.Usage)
+	now := time.Now().UTC()
+
+	record := models.UsageRecord{
+		ID:              primitive.NewObjectID(),
+		OrgID:           orgId,
+		SessionId:       sessionId,
+		Operation:       string(op),
+		Model:           resp.Model,
+		PromptTokens:    resp.Usage.PromptTokens,
+		CandidateTokens: resp.Usage.CandidateTokens,
+		TotalTokens:     resp.Usage.TotalTokens,
+		EstimatedCost:   cost,
+		Day:             now.Format("2006-01-02"),
+		CreatedAt:       now,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if usageStore != nil {
+		if err := usageStore.InsertUsage(ctx, record); err != nil {
+			log.Println("Failed to insert usage record:", err)
+		}
+	}
+
+	if sessionId.IsZero() {
+		return
+	}
+
+	totals := models.UsageTotals{
+		PromptTokens:    record.PromptTokens,
+		CandidateTokens: record.CandidateTokens,
+		TotalTokens:     record.TotalTokens,
+		EstimatedCost:   record.EstimatedCost,
+		Calls:           1,
+	}
+	if err := sessionStore.AddUsage(ctx, orgId, sessionId, totals); err != nil {
+		log.Println("Failed to update session usage:", err)
+	}
+}
+
+// GetDailyUsage sums the usage selected by filter per UTC day.
+func GetDailyUsage(filter store.UsageFilter) ([]models.DailyUsage, error) {
+	if usageStore == nil {
+		return nil, fmt.Errorf("usage accounting is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return usageStore.DailyUsage(ctx, filter)
+}
+
+// checkBudgets returns an error once the session, its organization or the
+// deployment has used up its token budget. The deployment budgets come from
+// SESSION_TOKEN_BUDGET and DAILY_TOKEN_BUDGET, the organization's from its
+// config; whichever is hit first applies. A session that hits its ceiling
+// is ended.
+func checkBudgets(session *models.Session, config models.OrgConfig) error {
+	for _, budget := range []int{tokenBudget("SESSION_TOKEN_BUDGET"), config.SessionTokenBudget} {
+		if budget > 0 && session.Usage.TotalTokens >= budget {
+			if _, err := TransitionSession(session, models.Ended, nil); err != nil {
+				log.Println("Failed to end session over budget:", err)
+			}
+			return fmt.Errorf("session token budget exhausted, the interview has been ended")
+		}
+	}
+
+	return checkDailyBudgets(session.OrgID, config)
+}
+
+// checkDailyBudgets returns an error once the deployment or the organization
+// orgId has used up its token budget for the day.
+func checkDailyBudgets(orgId primitive.ObjectID, config models.OrgConfig) error {
+	if usageStore == nil {
+		return nil
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	dailyBudgets := []struct {
+		budget int
+		filter store.UsageFilter
+	}{
+		{tokenBudget("DAILY_TOKEN_BUDGET"), store.UsageFilter{From: today, To: today, AllOrgs: true}},
+		{config.DailyTokenBudget, store.UsageFilter{From: today, To: today, OrgID: orgId}},
+	}
+	for _, daily := range dailyBudgets {
+		if daily.budget <= 0 {
+			continue
+		}
+		days, err := GetDailyUsage(daily.filter)
+		if err != nil {
+			log.Println("Failed to check daily budget:", err)
+		} else if len(days) > 0 && days[0].TotalTokens >= daily.budget {
+			return fmt.Errorf("daily token budget exhausted, please try again tomorrow")
+		}
+	}
+
+	return nil
+}
+
+// GetSessionUsage returns the running totals and individual calls for a session.
+func GetSessionUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	sessionId := mux.Vars(r)["sessionId"]
+	session, err := getViewableSession(r, sessionId)
+	if err != nil {
+		sessionErrorResponse(w, err, "Failed to fetch usage")
+		return
+	}
+
+	records := []models.UsageRecord{}
+	if usageStore != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		records, err = usageStore.ListUsage(ctx, session.OrgID, session.ID)
+		if err != nil {
+			log.Println("Failed to fetch usage:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch usage")
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "Session usage retrieved successfully", map[string]interface{}{
+		"sessionId": session.ID.Hex(),
+		"usage":     session.Usage,
+		"budget":    tokenBudget("SESSION_TOKEN_BUDGET"),
+		"orgBudget": requestTenant(r).config().SessionTokenBudget,
+		"calls":     records,
+	})
+}
+
+// GetUsageByDay returns the per-day totals of the request's organization,
+// optionally limited with ?from= and ?to= (YYYY-MM-DD). It is open to the
+// deployment admin, admin-scoped API keys and reviewers of the organization.
+func GetUsageByDay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tenant := requestTenant(r)
+	if !isAdmin(r) && !(tenant.viaAPIKey && tenant.scope == models.ScopeAdmin) {
+		if _, ok := requireReviewer(w, r); !ok {
+			return
+		}
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	for _, day := range []string{from, to} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "from and to must be dates in YYYY-MM-DD format")
+			return
+		}
+	}
+
+	if usageStore == nil {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, "Usage accounting is not configured")
+		return
+	}
+
+	days, err := GetDailyUsage(store.UsageFilter{From: from, To: to, OrgID: tenant.orgId})
+	if err != nil {
+		log.Println("Failed to fetch daily usage:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch daily usage")
+		return
+	}
+
+	utils.SuccessResponse(w, "Daily usage retrieved successfully", map[string]interface{}{
+		"days":      days,
+		"budget":    tokenBudget("DAILY_TOKEN_BUDGET"),
+		"orgBudget": tenant.config().DailyTokenBudget,
+	})
+}
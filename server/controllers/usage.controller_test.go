@@ -0,0 +1,47 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rnkp755/mockinterviewBackend/controllers"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+)
+
+func TestGetUsageByDayAccess(t *testing.T) {
+	server := newTestServer(t, llm.NewScriptedProvider())
+	controllers.SetAdminToken("admin-token")
+	t.Cleanup(func() { controllers.SetAdminToken("") })
+
+	resp, body := send(t, http.MethodPost, server.URL+"/api/v1/auth/signup",
+		`{"name": "Jane", "email": "jane@example.com", "password": "correct horse battery"}`, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("signing up: status %d: %s", resp.StatusCode, body.Message)
+	}
+	var signup struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body.Data, &signup); err != nil {
+		t.Fatalf("decoding signup: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"guest", nil, http.StatusUnauthorized},
+		{"candidate", map[string]string{"Authorization": "Bearer " + signup.Token}, http.StatusForbidden},
+		{"wrong admin token", map[string]string{controllers.AdminTokenHeader: "guess"}, http.StatusUnauthorized},
+		{"deployment admin", map[string]string{controllers.AdminTokenHeader: "admin-token"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := send(t, http.MethodGet, server.URL+"/api/v1/usage/daily", "", tt.headers)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d (%s), want %d", resp.StatusCode, body.Message, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,36 +13,95 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectToDb(collectionName string) *mongo.Collection {
+// client is shared by every collection. It is created once by Connect and
+// closed by Disconnect.
+var client *mongo.Client
 
-	connectionString := os.Getenv("MONGODB_URI")
-	dbName := os.Getenv("DB_NAME")
+// ClientOptionsFromEnv builds the client options from MONGODB_URI and the
+// optional MONGO_* pool and timeout settings.
+func ClientOptionsFromEnv() *options.ClientOptions {
+	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
+	opts := options.Client().
+		ApplyURI(os.Getenv("MONGODB_URI")).
+		SetServerAPIOptions(serverAPI).
+		SetConnectTimeout(10 * time.Second).
+		SetServerSelectionTimeout(10 * time.Second)
 
-	if connectionString == "" || dbName == "" {
-		log.Println("MONGODB_URI or DB_NAME not set")
-		return nil
+	uintEnv := func(key string, set func(uint64)) {
+		if v := os.Getenv(key); v != "" {
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+				set(n)
+			} else {
+				log.Printf("Warning: invalid %s %q: %v", key, v, err)
+			}
+		}
+	}
+	durationEnv := func(key string, set func(time.Duration)) {
+		if v := os.Getenv(key); v != "" {
+			if d, err := time.ParseDuration(v); err == nil {
+				set(d)
+			} else {
+				log.Printf("Warning: invalid %s %q: %v", key, v, err)
+			}
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	uintEnv("MONGO_MAX_POOL_SIZE", func(n uint64) { opts.SetMaxPoolSize(n) })
+	uintEnv("MONGO_MIN_POOL_SIZE", func(n uint64) { opts.SetMinPoolSize(n) })
+	durationEnv("MONGO_MAX_CONN_IDLE_TIME", func(d time.Duration) { opts.SetMaxConnIdleTime(d) })
+	durationEnv("MONGO_CONNECT_TIMEOUT", func(d time.Duration) { opts.SetConnectTimeout(d) })
+	durationEnv("MONGO_SERVER_SELECTION_TIMEOUT", func(d time.Duration) { opts.SetServerSelectionTimeout(d) })
 
-	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().
-		ApplyURI(connectionString).
-		SetServerAPIOptions(serverAPI)
+	return opts
+}
 
-	client, err := mongo.Connect(ctx, opts)
+// Connect creates the shared client and returns the DB_NAME database.
+// Calling it again returns the database of the existing client.
+func Connect(ctx context.Context) (*mongo.Database, error) {
+	dbName := os.Getenv("DB_NAME")
+	if os.Getenv("MONGODB_URI") == "" || dbName == "" {
+		return nil, fmt.Errorf("MONGODB_URI or DB_NAME not set")
+	}
+
+	if client != nil {
+		return client.Database(dbName), nil
+	}
+
+	c, err := mongo.Connect(ctx, ClientOptionsFromEnv())
 	if err != nil {
-		log.Println("Mongo connect error:", err)
-		return nil
+		return nil, fmt.Errorf("mongo connect error: %v", err)
 	}
 
-	if err := client.Database(dbName).RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err(); err != nil {
-		log.Println("Mongo ping error:", err)
-		return nil
+	if err := c.Database(dbName).RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err(); err != nil {
+		_ = c.Disconnect(context.Background())
+		return nil, fmt.Errorf("mongo ping error: %v", err)
 	}
 
 	fmt.Println("MongoDB connected successfully")
 
-	return client.Database(dbName).Collection(collectionName)
-}
\ No newline at end of file
+	client = c
+	return client.Database(dbName), nil
+}
+
+// Disconnect closes the shared client, waiting for in-flight operations
+// until ctx is done.
+func Disconnect(ctx context.Context) error {
+	if client == nil {
+		return nil
+	}
+	err := client.Disconnect(ctx)
+	client = nil
+	return err
+}
+
+// EnsureIndexes creates indexes on collection. Creating an index that
+// already exists is a no-op, so this is safe to run on every startup.
+func EnsureIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongo.IndexModel) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create indexes on %s: %v", collection.Name(), err)
+	}
+	return nil
+}
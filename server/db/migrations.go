@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// legacyQuestion is the pre-Turn question document, where every field was a
+// separate array indexed by question number.
+type legacyQuestion struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Question  []string           `bson:"question"`
+	Rating    []string           `bson:"rating"`
+	Review    []string           `bson:"review"`
+	Attempts  []int              `bson:"attempts"`
+	Answers   []legacyAnswer     `bson:"answers"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+type legacyAnswer struct {
+	Text       string              `bson:"text"`
+	Source     models.AnswerSource `bson:"source"`
+	AnsweredAt time.Time           `bson:"answeredAt"`
+}
+
+var (
+	legacyCodeRe         = regexp.MustCompile("(?s)^(.*?)\n```\n(.*)\n```$")
+	legacyPositiveRe     = regexp.MustCompile(`(?s)<Positive>(.*?)</Positive>`)
+	legacyNegativeRe     = regexp.MustCompile(`(?s)<Negative>(.*?)</Negative>`)
+	legacyImprovementsRe = regexp.MustCompile(`(?s)<Improvements>(.*?)</Improvements>`)
+)
+
+// MigrateQuestionTurns converts question documents still using the parallel
+// question/rating/review arrays into Turn subdocuments. Documents that
+// already have turns are left alone, so it is safe to run on every startup.
+func MigrateQuestionTurns(ctx context.Context, collection *mongo.Collection) (int, error) {
+	filter := bson.M{"turns": bson.M{"$exists": false}}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find legacy question documents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var legacy legacyQuestion
+		if err := cursor.Decode(&legacy); err != nil {
+			return migrated, fmt.Errorf("failed to decode legacy question document: %v", err)
+		}
+
+		update := bson.M{
+			"$set":   bson.M{"turns": legacyTurns(&legacy)},
+			"$unset": bson.M{"question": "", "rating": "", "review": "", "attempts": "", "answers": ""},
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": legacy.ID, "turns": bson.M{"$exists": false}}, update); err != nil {
+			return migrated, fmt.Errorf("failed to migrate question document %s: %v", legacy.ID.Hex(), err)
+		}
+		migrated++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return migrated, err
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d question documents to turns", migrated)
+	}
+	return migrated, nil
+}
+
+func legacyTurns(legacy *legacyQuestion) []models.Turn {
+	turns := make([]models.Turn, 0, len(legacy.Question))
+
+	for i, text := range legacy.Question {
+		turn := models.Turn{
+			Number:   i + 1,
+			Question: text,
+			// The old documents did not record when each question was asked.
+			AskedAt: legacy.CreatedAt,
+		}
+
+		// Code used to be appended to the question inside a fenced block
+		if m := legacyCodeRe.FindStringSubmatch(text); m != nil {
+			turn.Question = m[1]
+			turn.Code = m[2]
+		}
+
+		if i < len(legacy.Attempts) {
+			turn.Attempts = legacy.Attempts[i]
+		}
+
+		if i < len(legacy.Answers) {
+			a := legacy.Answers[i]
+			turn.Answer = &models.Answer{Text: a.Text, Source: a.Source}
+			if !a.AnsweredAt.IsZero() {
+				answeredAt := a.AnsweredAt
+				turn.AnsweredAt = &answeredAt
+			}
+		}
+
+		if i < len(legacy.Rating) {
+			if rating, err := strconv.Atoi(strings.TrimSpace(legacy.Rating[i])); err == nil {
+				turn.Rating = &rating
+			}
+		}
+
+		if i < len(legacy.Review) {
+			turn.Feedback = legacyFeedback(legacy.Review[i])
+		}
+
+		turns = append(turns, turn)
+	}
+
+	return turns
+}
+
+// legacyFeedback parses the tagged review string. Reviews without the tags
+// are kept whole as improvements so no text is lost.
+func legacyFeedback(review string) *models.Feedback {
+	review = strings.TrimSpace(review)
+	if review == "" {
+		return nil
+	}
+
+	extract := func(re *regexp.Regexp) string {
+		if m := re.FindStringSubmatch(review); m != nil {
+			return strings.TrimSpace(m[1])
+		}
+		return ""
+	}
+
+	feedback := &models.Feedback{
+		Positive:     extract(legacyPositiveRe),
+		Negative:     extract(legacyNegativeRe),
+		Improvements: extract(legacyImprovementsRe),
+	}
+	if feedback.Positive == "" && feedback.Negative == "" && feedback.Improvements == "" {
+		feedback.Improvements = review
+	}
+	return feedback
+}
+
+// duplicateQuestions is a session with more than one question document.
+type duplicateQuestions struct {
+	SessionId primitive.ObjectID `bson:"_id"`
+	Documents []struct {
+		ID        primitive.ObjectID `bson:"id"`
+		Turns     int                `bson:"turns"`
+		UpdatedAt time.Time          `bson:"updatedAt"`
+	} `bson:"documents"`
+}
+
+// DedupeQuestions keeps one question document per session so the unique
+// sessionid index can be built. Older deployments could write a second
+// document when the first question was requested twice. The document with
+// the most turns is kept, the most recently updated one on a tie; the others
+// are moved to archive rather than deleted. It is a no-op once the index
+// exists, so it is safe to run on every startup.
+func DedupeQuestions(ctx context.Context, collection *mongo.Collection, archive *mongo.Collection) (int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$sessionid",
+			"count": bson.M{"$sum": 1},
+			"documents": bson.M{"$push": bson.M{
+				"id":        "$_id",
+				"turns":     bson.M{"$size": bson.M{"$ifNull": bson.A{"$turns", bson.A{}}}},
+				"updatedAt": "$updatedAt",
+			}},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find duplicate question documents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	archived := 0
+	for cursor.Next(ctx) {
+		var duplicate duplicateQuestions
+		if err := cursor.Decode(&duplicate); err != nil {
+			return archived, fmt.Errorf("failed to decode duplicate question documents: %v", err)
+		}
+
+		keep := 0
+		for i, doc := range duplicate.Documents {
+			best := duplicate.Documents[keep]
+			if doc.Turns > best.Turns || (doc.Turns == best.Turns && doc.UpdatedAt.After(best.UpdatedAt)) {
+				keep = i
+			}
+		}
+
+		for i, doc := range duplicate.Documents {
+			if i == keep {
+				continue
+			}
+			if err := archiveQuestion(ctx, collection, archive, doc.ID); err != nil {
+				return archived, err
+			}
+			archived++
+		}
+		log.Printf("Kept question document %s of session %s and archived %d duplicates",
+			duplicate.Documents[keep].ID.Hex(), duplicate.SessionId.Hex(), len(duplicate.Documents)-1)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return archived, err
+	}
+	return archived, nil
+}
+
+// archiveQuestion moves one question document to archive.
+func archiveQuestion(ctx context.Context, collection *mongo.Collection, archive *mongo.Collection, id primitive.ObjectID) error {
+	var document bson.M
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document); err != nil {
+		return fmt.Errorf("failed to read duplicate question document %s: %v", id.Hex(), err)
+	}
+
+	// An earlier, interrupted run may have archived it already
+	if _, err := archive.InsertOne(ctx, document); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to archive duplicate question document %s: %v", id.Hex(), err)
+	}
+	if _, err := collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete duplicate question document %s: %v", id.Hex(), err)
+	}
+	return nil
+}
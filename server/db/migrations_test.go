@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestDedupeQuestions runs against the MongoDB at MONGODB_TEST_URI, in a
+// throwaway database.
+func TestDedupeQuestions(t *testing.T) {
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connecting to MongoDB: %v", err)
+	}
+	defer c.Disconnect(context.Background())
+
+	database := c.Database("dedupe_" + primitive.NewObjectID().Hex())
+	defer database.Drop(context.Background())
+	questions := database.Collection("questions")
+	archive := database.Collection("questions_duplicates")
+
+	duplicated, single := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Now()
+	documents := []any{
+		bson.M{"_id": primitive.NewObjectID(), "sessionid": duplicated, "turns": bson.A{bson.M{"number": 1}}, "updatedAt": now},
+		bson.M{"_id": primitive.NewObjectID(), "sessionid": duplicated, "turns": bson.A{bson.M{"number": 1}, bson.M{"number": 2}}, "updatedAt": now.Add(-time.Hour)},
+		bson.M{"_id": primitive.NewObjectID(), "sessionid": duplicated, "turns": bson.A{}, "updatedAt": now},
+		bson.M{"_id": primitive.NewObjectID(), "sessionid": single, "turns": bson.A{}, "updatedAt": now},
+	}
+	if _, err := questions.InsertMany(ctx, documents); err != nil {
+		t.Fatalf("inserting question documents: %v", err)
+	}
+
+	archived, err := DedupeQuestions(ctx, questions, archive)
+	if err != nil {
+		t.Fatalf("DedupeQuestions: %v", err)
+	}
+	if archived != 2 {
+		t.Errorf("archived %d documents, want 2", archived)
+	}
+
+	// The document with the most turns is kept
+	var kept struct {
+		Turns []bson.M `bson:"turns"`
+	}
+	if err := questions.FindOne(ctx, bson.M{"sessionid": duplicated}).Decode(&kept); err != nil || len(kept.Turns) != 2 {
+		t.Errorf("kept document has %d turns, %v; want 2", len(kept.Turns), err)
+	}
+	if n, err := archive.CountDocuments(ctx, bson.M{"sessionid": duplicated}); err != nil || n != 2 {
+		t.Errorf("archive holds %d documents, %v; want 2", n, err)
+	}
+
+	if err := EnsureIndexes(ctx, questions, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "sessionid", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}); err != nil {
+		t.Errorf("unique index after deduplication: %v", err)
+	}
+}
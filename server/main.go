@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/rnkp755/mockinterviewBackend/controllers"
 	"github.com/rnkp755/mockinterviewBackend/routes"
+	"github.com/rnkp755/mockinterviewBackend/services/auth"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+	"github.com/rnkp755/mockinterviewBackend/store"
 	"github.com/rs/cors"
 )
 
@@ -26,6 +33,43 @@ func main() {
 		port = "8080" // fallback for local dev
 	}
 
+	// Initialize LLM provider
+	provider, err := llm.NewFromEnv(context.Background())
+	if err != nil {
+		log.Fatal("Failed to initialize LLM provider:", err)
+	}
+	policy := llm.PolicyFromEnv()
+	controllers.SetLLMProvider(llm.NewResilientProvider(provider, policy))
+	controllers.SetLLMPolicy(policy)
+	log.Println("Using LLM provider:", provider.Name())
+
+	// Initialize storage
+	stores, err := store.NewFromEnv(context.Background())
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+	controllers.SetStores(stores)
+	log.Println("Using storage backend:", stores.Backend)
+
+	// Initialize authentication
+	tokenIssuer, err := auth.NewTokenIssuerFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize authentication:", err)
+	}
+	controllers.SetTokenIssuer(tokenIssuer)
+
+	// Organization management is only enabled with ADMIN_TOKEN
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		controllers.SetAdminToken(adminToken)
+	} else {
+		log.Println("ADMIN_TOKEN not set. Organization management is disabled.")
+	}
+
+	// Expire stale sessions in the background until shutdown
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	controllers.StartExpirySweeper(sweepCtx, controllers.SessionLifetimeFromEnv())
+
 	// Initialize router
 	router := routes.Router()
 
@@ -43,12 +87,15 @@ func main() {
 			http.MethodDelete,
 			http.MethodOptions,
 		},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Idempotency-Key", controllers.GuestTokenHeader, controllers.APIKeyHeader, controllers.AdminTokenHeader},
+		ExposedHeaders:   []string{"Retry-After", controllers.GuestTokenHeader},
 		AllowCredentials: true,
 	})
 
 	handler := corsHandler.Handler(router)
 
+	// WriteTimeout suits ordinary requests; handlers that wait on the model
+	// extend their own deadline from the LLM policy
 	server := &http.Server{
 		Addr:         ":" + port,
 		Handler:      handler,
@@ -57,9 +104,29 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	log.Println("Server running on port:", port)
+	// Stop accepting requests on SIGINT/SIGTERM, let in-flight ones finish,
+	// then close the database connections
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		log.Println("Server running on port:", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Server failed:", err)
+		}
+	}()
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal("Server failed:", err)
+	<-stop
+	log.Println("Shutting down ...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stopSweeper()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Server shutdown error:", err)
+	}
+	if err := stores.Close(ctx); err != nil {
+		log.Println("Storage shutdown error:", err)
 	}
-}
\ No newline at end of file
+}
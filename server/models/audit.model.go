@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditAction names something that happened to a session outside the
+// interview itself.
+type AuditAction string
+
+const (
+	// AuditSessionClaimed records a guest session moving into a user's
+	// account.
+	AuditSessionClaimed AuditAction = "session.claimed"
+	// AuditTurnReviewed records a reviewer overriding the AI rating of a
+	// turn.
+	AuditTurnReviewed AuditAction = "turn.reviewed"
+)
+
+// AuditEvent is one entry of the audit trail. Events are only ever
+// appended.
+type AuditEvent struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	OrgID     primitive.ObjectID `json:"orgID,omitempty" bson:"orgID,omitempty"`
+	Action    AuditAction        `json:"action" bson:"action"`
+	ActorID   primitive.ObjectID `json:"actorID" bson:"actorID"`
+	SessionID primitive.ObjectID `json:"sessionID" bson:"sessionID"`
+	Details   map[string]string  `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
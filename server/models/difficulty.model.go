@@ -0,0 +1,151 @@
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+// Questions are asked at a difficulty from MinDifficulty to MaxDifficulty.
+// The candidate's ability is estimated on the same scale, so a candidate
+// with ability 3 is expected to score half marks on a level 3 question.
+const (
+	MinDifficulty = 1
+	MaxDifficulty = 5
+)
+
+// difficultyLabels describe each level to the interviewer model.
+var difficultyLabels = map[int]string{
+	1: "fundamentals",
+	2: "easy",
+	3: "medium",
+	4: "hard",
+	5: "expert",
+}
+
+// DifficultyLabel names a difficulty level.
+func DifficultyLabel(difficulty int) string {
+	return difficultyLabels[clampDifficulty(difficulty)]
+}
+
+func clampDifficulty(difficulty int) int {
+	return min(max(difficulty, MinDifficulty), MaxDifficulty)
+}
+
+// InitialAbility is the ability assumed before any answer is rated, from the
+// experience the candidate gave.
+func InitialAbility(experience string) float64 {
+	switch strings.ToLower(strings.TrimSpace(experience)) {
+	case "2+ years":
+		return 3.5
+	case "0-2 years":
+		return 2.5
+	default:
+		return 2
+	}
+}
+
+// CurrentAbility returns the session's ability estimate. Sessions created
+// before it was tracked start from the candidate's experience.
+func (s *Session) CurrentAbility() float64 {
+	if s.Ability == 0 {
+		return InitialAbility(s.Experience)
+	}
+	return s.Ability
+}
+
+// DifficultyFor returns the level to ask a candidate of the given ability.
+func DifficultyFor(ability float64) int {
+	return clampDifficulty(int(math.Round(ability)))
+}
+
+// UpdateAbility is an Elo-style update of the ability estimate after an
+// answer to a question of the given difficulty was rated. The expected score
+// follows a one-parameter IRT (Rasch) curve of ability minus difficulty, and
+// the step shrinks as more answers are rated, so early answers move the
+// estimate quickly and later ones refine it. answered is the number of
+// answers rated before this one.
+func UpdateAbility(ability float64, difficulty int, rating int, answered int) float64 {
+	expected := 1 / (1 + math.Exp(-(ability - float64(difficulty))))
+	actual := float64(rating-MinRating) / float64(MaxRating-MinRating)
+	step := max(1.5/math.Sqrt(float64(answered+1)), 0.5)
+
+	// Keep the estimate within half a level of the scale
+	ability += step * (actual - expected)
+	ability = math.Max(ability, MinDifficulty-0.5)
+	ability = math.Min(ability, MaxDifficulty+0.5)
+	return math.Round(ability*100) / 100
+}
+
+// DifficultyStep is the level the next question is asked at when the
+// current answer is rated from MinRating to MaxRating.
+type DifficultyStep struct {
+	MinRating  int
+	MaxRating  int
+	Difficulty int
+}
+
+// DifficultyPlan is the adaptive difficulty state of a turn being answered.
+type DifficultyPlan struct {
+	// Current is the level of the question being answered.
+	Current int
+	// Ability is the estimate before the current answer is rated.
+	Ability float64
+	// Answered is the number of answers rated before the current one.
+	Answered int
+}
+
+// PlanDifficulty returns the difficulty state of the session's current turn.
+// A current turn from before difficulty was tracked is taken to be at the
+// level of the current ability.
+func PlanDifficulty(session *Session, questions *Question) DifficultyPlan {
+	plan := DifficultyPlan{Ability: session.CurrentAbility()}
+
+	current := questions.CurrentTurn()
+	if current == nil {
+		plan.Current = DifficultyFor(plan.Ability)
+		return plan
+	}
+
+	plan.Current = current.Difficulty
+	if plan.Current == 0 {
+		plan.Current = DifficultyFor(plan.Ability)
+	}
+	for _, turn := range questions.Turns[:len(questions.Turns)-1] {
+		if turn.Rating != nil {
+			plan.Answered++
+		}
+	}
+	return plan
+}
+
+// Next returns the ability estimate after the current answer is rated and
+// the level of the next question.
+func (p DifficultyPlan) Next(rating int) (float64, int) {
+	ability := UpdateAbility(p.Ability, p.Current, rating, p.Answered)
+	return ability, DifficultyFor(ability)
+}
+
+// Steps lists the level of the next question for every rating, merging
+// consecutive ratings that lead to the same level.
+func (p DifficultyPlan) Steps() []DifficultyStep {
+	var steps []DifficultyStep
+	for rating := MinRating; rating <= MaxRating; rating++ {
+		_, next := p.Next(rating)
+		if n := len(steps); n > 0 && steps[n-1].Difficulty == next {
+			steps[n-1].MaxRating = rating
+			continue
+		}
+		steps = append(steps, DifficultyStep{MinRating: rating, MaxRating: rating, Difficulty: next})
+	}
+	return steps
+}
+
+// DifficultyPoint is one turn of the difficulty trajectory in a report.
+// Rating is the AI rating that drove the adaptation, and Ability the
+// estimate after it.
+type DifficultyPoint struct {
+	Turn       int      `json:"turn" bson:"turn"`
+	Difficulty int      `json:"difficulty" bson:"difficulty"`
+	Rating     *int     `json:"rating,omitempty" bson:"rating,omitempty"`
+	Ability    *float64 `json:"ability,omitempty" bson:"ability,omitempty"`
+}
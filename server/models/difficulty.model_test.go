@@ -0,0 +1,132 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdateAbility(t *testing.T) {
+	tests := []struct {
+		name       string
+		ability    float64
+		difficulty int
+		rating     int
+		answered   int
+		check      func(updated float64) bool
+		want       string
+	}{
+		{"a high rating raises ability", 3, 3, 9, 0, func(a float64) bool { return a > 3 }, "above 3"},
+		{"a low rating lowers ability", 3, 3, 1, 0, func(a float64) bool { return a < 3 }, "below 3"},
+		{"half marks at the matching level keep ability", 3, 3, 5, 0, func(a float64) bool { return a == 3 }, "3"},
+		{"the estimate is capped above the top level", 5.5, 1, MaxRating, 0, func(a float64) bool { return a == MaxDifficulty+0.5 }, "5.5"},
+		{"the estimate is floored below the bottom level", 0.5, 5, MinRating, 0, func(a float64) bool { return a == MinDifficulty-0.5 }, "0.5"},
+		{"a large step from the top stays clamped", 5.4, 5, MaxRating, 0, func(a float64) bool { return a == MaxDifficulty+0.5 }, "5.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpdateAbility(tt.ability, tt.difficulty, tt.rating, tt.answered)
+			if !tt.check(got) {
+				t.Errorf("UpdateAbility(%v, %d, %d, %d) = %v, want %s", tt.ability, tt.difficulty, tt.rating, tt.answered, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAbilityStepShrinks(t *testing.T) {
+	// The same surprise moves the estimate less the more answers were rated,
+	// down to the minimum step
+	previous := math.Inf(1)
+	for answered := 0; answered <= 12; answered++ {
+		step := UpdateAbility(3, 3, MaxRating, answered) - 3
+		if step <= 0 || step > previous {
+			t.Errorf("step after %d answers = %v, want positive and at most %v", answered, step, previous)
+		}
+		previous = step
+	}
+	if first, late := UpdateAbility(3, 3, MaxRating, 0)-3, UpdateAbility(3, 3, MaxRating, 12)-3; late >= first {
+		t.Errorf("step after 12 answers = %v, want less than the first step %v", late, first)
+	}
+	if floor := UpdateAbility(3, 3, MaxRating, 100) - 3; floor != UpdateAbility(3, 3, MaxRating, 1000)-3 {
+		t.Errorf("step keeps shrinking past the minimum: %v", floor)
+	}
+}
+
+func TestPlanDifficulty(t *testing.T) {
+	rating := 7
+	tests := []struct {
+		name      string
+		session   Session
+		questions *Question
+		want      DifficultyPlan
+	}{
+		{
+			name:    "before the first question",
+			session: Session{Experience: "2+ years"},
+			want:    DifficultyPlan{Current: 4, Ability: 3.5},
+		},
+		{
+			name:      "a turn from before difficulty was tracked",
+			session:   Session{Ability: 2.4},
+			questions: &Question{Turns: []Turn{{Number: 1}}},
+			want:      DifficultyPlan{Current: 2, Ability: 2.4},
+		},
+		{
+			name:    "counts the rated answers before the current turn",
+			session: Session{Ability: 3.2},
+			questions: &Question{Turns: []Turn{
+				{Number: 1, Difficulty: 2, Rating: &rating},
+				{Number: 2, Difficulty: 3},
+				{Number: 3, Difficulty: 3, Rating: &rating},
+				{Number: 4, Difficulty: 4},
+			}},
+			want: DifficultyPlan{Current: 4, Ability: 3.2, Answered: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlanDifficulty(&tt.session, tt.questions); got != tt.want {
+				t.Errorf("PlanDifficulty = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDifficultyPlanSteps(t *testing.T) {
+	plans := []DifficultyPlan{
+		{Current: 1, Ability: 0.5},
+		{Current: 3, Ability: 3},
+		{Current: 5, Ability: 5.5},
+		{Current: 3, Ability: 3, Answered: 20},
+	}
+	for _, plan := range plans {
+		steps := plan.Steps()
+		if len(steps) == 0 {
+			t.Fatalf("%+v: no steps", plan)
+		}
+
+		// The steps cover every rating once, in order, each with the level
+		// Next picks and a different level from its neighbour
+		if steps[0].MinRating != MinRating || steps[len(steps)-1].MaxRating != MaxRating {
+			t.Errorf("%+v: steps cover %d..%d, want %d..%d", plan, steps[0].MinRating, steps[len(steps)-1].MaxRating, MinRating, MaxRating)
+		}
+		for i, step := range steps {
+			if step.MinRating > step.MaxRating {
+				t.Errorf("%+v: step %d covers %d..%d", plan, i, step.MinRating, step.MaxRating)
+			}
+			if i > 0 && step.MinRating != steps[i-1].MaxRating+1 {
+				t.Errorf("%+v: step %d starts at %d after %d", plan, i, step.MinRating, steps[i-1].MaxRating)
+			}
+			if i > 0 && step.Difficulty == steps[i-1].Difficulty {
+				t.Errorf("%+v: steps %d and %d both lead to level %d", plan, i-1, i, step.Difficulty)
+			}
+			for rating := step.MinRating; rating <= step.MaxRating; rating++ {
+				if _, next := plan.Next(rating); next != step.Difficulty {
+					t.Errorf("%+v: rating %d leads to level %d, step says %d", plan, rating, next, step.Difficulty)
+				}
+			}
+			if step.Difficulty < MinDifficulty || step.Difficulty > MaxDifficulty {
+				t.Errorf("%+v: step %d leads to level %d", plan, i, step.Difficulty)
+			}
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Content struct {
 	Parts []string `json:"Parts"`
 	Role  string   `json:"Role"`
@@ -32,9 +37,49 @@ type GeminniResponse struct {
 	UsageMetadata  UsageMetadata `json:"UsageMetadata"`
 }
 
+// Feedback is the interviewer's structured review of an answer.
+type Feedback struct {
+	Positive     string `json:"positive" bson:"positive"`
+	Negative     string `json:"negative" bson:"negative"`
+	Improvements string `json:"improvements" bson:"improvements"`
+}
+
+// ExtractedResponse is the typed reply of the interviewer model. Rating and
+// Feedback are only present when the model evaluated an answer.
 type ExtractedResponse struct {
-    Rating    string
-    Feedback  string
-    Question string
-	Code string
-}
\ No newline at end of file
+	Rating   *int      `json:"rating,omitempty"`
+	Feedback *Feedback `json:"feedback,omitempty"`
+	Question string    `json:"question"`
+	Code     string    `json:"code"`
+}
+
+// Validate reports every problem with the response. requireEvaluation is set
+// for follow-up turns, where a rating and feedback are mandatory.
+func (e *ExtractedResponse) Validate(requireEvaluation bool) error {
+	var problems []string
+
+	if strings.TrimSpace(e.Question) == "" {
+		problems = append(problems, "question is missing")
+	}
+
+	if requireEvaluation {
+		if e.Rating == nil {
+			problems = append(problems, "rating is missing")
+		} else if *e.Rating < 0 || *e.Rating > 10 {
+			problems = append(problems, fmt.Sprintf("rating %d is outside 0-10", *e.Rating))
+		}
+
+		if e.Feedback == nil {
+			problems = append(problems, "feedback is missing")
+		} else if strings.TrimSpace(e.Feedback.Positive) == "" &&
+			strings.TrimSpace(e.Feedback.Negative) == "" &&
+			strings.TrimSpace(e.Feedback.Improvements) == "" {
+			problems = append(problems, "feedback is empty")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("malformed interviewer response: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+type AllowedInterviewStatus string
+
+const (
+	NotStarted       AllowedInterviewStatus = "not-started"
+	Evaluating       AllowedInterviewStatus = "evaluating"
+	WaitingForAnswer AllowedInterviewStatus = "waiting-for-answer"
+	Paused           AllowedInterviewStatus = "paused"
+	Ended            AllowedInterviewStatus = "ended"
+	Expired          AllowedInterviewStatus = "expired"
+)
+
+// interviewTransitions is the interview state machine: the statuses each
+// status may move to. Ended and Expired are terminal.
+//
+//	not-started ──> evaluating ──> waiting-for-answer <──> paused
+//	                    ^                 │
+//	                    └─────────────────┘
+//
+// A turn that fails while evaluating returns to the status it came from.
+// Any non-terminal status may be ended or expire.
+var interviewTransitions = map[AllowedInterviewStatus][]AllowedInterviewStatus{
+	NotStarted:       {Evaluating, Ended, Expired},
+	Evaluating:       {WaitingForAnswer, NotStarted, Ended, Expired},
+	WaitingForAnswer: {Evaluating, Paused, Ended, Expired},
+	Paused:           {WaitingForAnswer, Ended, Expired},
+}
+
+// ErrIllegalTransition is matched by errors.Is for every TransitionError.
+var ErrIllegalTransition = errors.New("illegal interview status transition")
+
+// TransitionError is returned for a transition the state machine forbids.
+type TransitionError struct {
+	From AllowedInterviewStatus
+	To   AllowedInterviewStatus
+}
+
+func (e *TransitionError) Error() string {
+	if e.From.IsTerminal() {
+		return fmt.Sprintf("interview has already %s", e.From)
+	}
+	return fmt.Sprintf("interview cannot move from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
+// StatusesLeadingTo returns every status that may move to next.
+func StatusesLeadingTo(next AllowedInterviewStatus) []AllowedInterviewStatus {
+	var from []AllowedInterviewStatus
+	for status := range interviewTransitions {
+		if status.CanTransitionTo(next) {
+			from = append(from, status)
+		}
+	}
+	return from
+}
+
+// IsValid reports whether s is a known status.
+func (s AllowedInterviewStatus) IsValid() bool {
+	_, ok := interviewTransitions[s]
+	return ok || s.IsTerminal()
+}
+
+// IsTerminal reports whether the interview is over.
+func (s AllowedInterviewStatus) IsTerminal() bool {
+	return s == Ended || s == Expired
+}
+
+// CanTransitionTo reports whether the state machine allows moving to next.
+func (s AllowedInterviewStatus) CanTransitionTo(next AllowedInterviewStatus) bool {
+	for _, allowed := range interviewTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition returns a *TransitionError unless moving to next is allowed.
+func (s AllowedInterviewStatus) Transition(next AllowedInterviewStatus) error {
+	if !s.CanTransitionTo(next) {
+		return &TransitionError{From: s, To: next}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxReviewNoteLength bounds the text of a single note.
+const MaxReviewNoteLength = 5000
+
+// ReviewNote is a reviewer's comment on a session. Notes are only visible
+// to reviewers and admins of the session's organization.
+type ReviewNote struct {
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	OrgID      primitive.ObjectID `json:"orgID,omitempty" bson:"orgID,omitempty"`
+	SessionID  primitive.ObjectID `json:"sessionID" bson:"sessionID"`
+	AuthorID   primitive.ObjectID `json:"authorID" bson:"authorID"`
+	AuthorName string             `json:"authorName" bson:"authorName"`
+	Text       string             `json:"text" bson:"text"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Validate normalizes the note's text and checks its length.
+func (n *ReviewNote) Validate() error {
+	n.Text = strings.TrimSpace(n.Text)
+	if n.Text == "" {
+		return errors.New("text is required")
+	}
+	if len([]rune(n.Text)) > MaxReviewNoteLength {
+		return fmt.Errorf("text must be at most %d characters", MaxReviewNoteLength)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrgConfig customizes interviews for one organization. Zero values fall
+// back to the deployment defaults.
+type OrgConfig struct {
+	// Model overrides the LLM model for the organization's interviews.
+	Model string `json:"model,omitempty" bson:"model,omitempty"`
+	// Persona replaces the default interviewer persona in every prompt.
+	Persona string `json:"persona,omitempty" bson:"persona,omitempty"`
+	// AllowedTechStacks limits the tech stacks a session may list. Empty
+	// allows any.
+	AllowedTechStacks []string `json:"allowedTechStacks,omitempty" bson:"allowedTechStacks,omitempty"`
+	// MaxSessionsPerDay caps the sessions created per UTC day.
+	MaxSessionsPerDay int `json:"maxSessionsPerDay,omitempty" bson:"maxSessionsPerDay,omitempty"`
+	// SessionTokenBudget caps the tokens a single session may use.
+	SessionTokenBudget int `json:"sessionTokenBudget,omitempty" bson:"sessionTokenBudget,omitempty"`
+	// DailyTokenBudget caps the tokens used per UTC day across the
+	// organization's sessions.
+	DailyTokenBudget int `json:"dailyTokenBudget,omitempty" bson:"dailyTokenBudget,omitempty"`
+}
+
+// Validate normalizes the config and checks its limits.
+func (c *OrgConfig) Validate() error {
+	c.Model = strings.TrimSpace(c.Model)
+	c.Persona = strings.TrimSpace(c.Persona)
+
+	stacks := make([]string, 0, len(c.AllowedTechStacks))
+	for _, stack := range c.AllowedTechStacks {
+		if stack = strings.TrimSpace(stack); stack != "" {
+			stacks = append(stacks, stack)
+		}
+	}
+	c.AllowedTechStacks = stacks
+
+	if c.MaxSessionsPerDay < 0 || c.SessionTokenBudget < 0 || c.DailyTokenBudget < 0 {
+		return errors.New("quotas must not be negative")
+	}
+	return nil
+}
+
+// AllowsTechStack reports whether sessions may list stack.
+func (c *OrgConfig) AllowsTechStack(stack string) bool {
+	if len(c.AllowedTechStacks) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedTechStacks {
+		if strings.EqualFold(allowed, strings.TrimSpace(stack)) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScope is what an API key may do for its organization.
+type APIKeyScope string
+
+const (
+	// ScopeClient keys are embedded in the organization's frontend: they
+	// enroll users and create sessions in the organization.
+	ScopeClient APIKeyScope = "client"
+	// ScopeAdmin keys also manage the organization: its config, its keys and
+	// its users' roles.
+	ScopeAdmin APIKeyScope = "admin"
+)
+
+// IsValid reports whether s is a known scope.
+func (s APIKeyScope) IsValid() bool {
+	return s == ScopeClient || s == ScopeAdmin
+}
+
+// APIKey authenticates calls made on behalf of an organization. Only a hash
+// of the key is stored; the key itself is shown once when it is created.
+type APIKey struct {
+	ID        string      `json:"id" bson:"id"`
+	Name      string      `json:"name" bson:"name"`
+	Scope     APIKeyScope `json:"scope" bson:"scope,omitempty"`
+	Hash      string      `json:"-" bson:"hash"`
+	CreatedAt time.Time   `json:"createdAt" bson:"createdAt"`
+	RevokedAt *time.Time  `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+}
+
+// ScopeOrDefault returns the key's scope. Keys created before scopes existed
+// are client keys, so they can no longer administer the organization.
+func (k *APIKey) ScopeOrDefault() APIKeyScope {
+	if k.Scope == "" {
+		return ScopeClient
+	}
+	return k.Scope
+}
+
+// KeyByHash returns the unrevoked key that hashes to hash, or nil.
+func (o *Organization) KeyByHash(hash string) *APIKey {
+	for i := range o.APIKeys {
+		if o.APIKeys[i].Hash == hash && o.APIKeys[i].RevokedAt == nil {
+			return &o.APIKeys[i]
+		}
+	}
+	return nil
+}
+
+// Organization is a tenant of the deployment. Its sessions, turns and users
+// are invisible to every other tenant.
+type Organization struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Config    OrgConfig          `json:"config" bson:"config"`
+	APIKeys   []APIKey           `json:"apiKeys" bson:"apiKeys"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
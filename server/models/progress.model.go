@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProgressPeriod is the bucket size of a progress time series.
+type ProgressPeriod string
+
+const (
+	ProgressDay   ProgressPeriod = "day"
+	ProgressWeek  ProgressPeriod = "week"
+	ProgressMonth ProgressPeriod = "month"
+)
+
+// ParseProgressPeriod validates a client-supplied period. An empty period
+// defaults to weeks.
+func ParseProgressPeriod(period string) (ProgressPeriod, error) {
+	switch p := ProgressPeriod(strings.ToLower(strings.TrimSpace(period))); p {
+	case "":
+		return ProgressWeek, nil
+	case ProgressDay, ProgressWeek, ProgressMonth:
+		return p, nil
+	default:
+		return "", fmt.Errorf("period should be one of 'day', 'week' or 'month'")
+	}
+}
+
+// Truncate returns the start of the period containing t, in UTC. Weeks start
+// on Monday.
+func (p ProgressPeriod) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case ProgressMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case ProgressWeek:
+		// time.Weekday counts from Sunday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return day
+	}
+}
+
+// ProgressPoint is the average rating of one area, a tech stack or a topic,
+// over the sessions created in one period.
+type ProgressPoint struct {
+	Area      string    `json:"area" bson:"area"`
+	Period    time.Time `json:"period" bson:"period"`
+	Score     float64   `json:"score" bson:"score"`
+	Sessions  int       `json:"sessions" bson:"sessions"`
+	Questions int       `json:"questions" bson:"questions"`
+}
+
+// ProgressTrend classifies how an area's score moved over time.
+type ProgressTrend string
+
+const (
+	Improving  ProgressTrend = "improving"
+	Regressing ProgressTrend = "regressing"
+	Steady     ProgressTrend = "steady"
+	// NotEnoughData is used for areas seen in a single period.
+	NotEnoughData ProgressTrend = "not-enough-data"
+)
+
+// AreaProgress summarizes the time series of one area. Change is the move
+// of the fitted trend line from the first period to the latest.
+type AreaProgress struct {
+	Area        string          `json:"area"`
+	Points      []ProgressPoint `json:"points"`
+	LatestScore float64         `json:"latestScore"`
+	Change      float64         `json:"change"`
+	Trend       ProgressTrend   `json:"trend"`
+}
@@ -1,17 +1,147 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// AnswerSource records how the candidate gave an answer.
+type AnswerSource string
+
+const (
+	AnswerTyped  AnswerSource = "typed"
+	AnswerSpeech AnswerSource = "speech"
+	AnswerCode   AnswerSource = "code"
+)
+
+// ParseAnswerSource validates a client-supplied source. An empty source
+// defaults to typed.
+func ParseAnswerSource(source string) (AnswerSource, error) {
+	switch s := AnswerSource(strings.ToLower(strings.TrimSpace(source))); s {
+	case "":
+		return AnswerTyped, nil
+	case AnswerTyped, AnswerSpeech, AnswerCode:
+		return s, nil
+	default:
+		return "", fmt.Errorf("answer source should be one of 'typed', 'speech' or 'code'")
+	}
+}
+
+// Answer is what the candidate said in reply to a question.
+type Answer struct {
+	Text   string       `json:"text" bson:"text"`
+	Source AnswerSource `json:"source" bson:"source"`
+}
+
+// Ratings range from MinRating to MaxRating, for the AI grader and human
+// reviewers alike.
+const (
+	MinRating = 0
+	MaxRating = 10
+)
+
+// TurnReview is a human reviewer's override of the AI evaluation of a turn.
+// Feedback is optional; without it the AI feedback stands.
+type TurnReview struct {
+	Rating       int                `json:"rating" bson:"rating"`
+	Feedback     *Feedback          `json:"feedback,omitempty" bson:"feedback,omitempty"`
+	ReviewerID   primitive.ObjectID `json:"reviewerID" bson:"reviewerID"`
+	ReviewerName string             `json:"reviewerName" bson:"reviewerName"`
+	ReviewedAt   time.Time          `json:"reviewedAt" bson:"reviewedAt"`
+}
+
+// Validate checks the review's rating.
+func (r *TurnReview) Validate() error {
+	if r.Rating < MinRating || r.Rating > MaxRating {
+		return fmt.Errorf("rating must be an integer from %d to %d", MinRating, MaxRating)
+	}
+	return nil
+}
+
+// Turn is one question of the interview together with the candidate's answer
+// and the interviewer's evaluation of it. Answer, Rating and Feedback stay
+// empty until the candidate has answered. Rating and Feedback always hold
+// the AI evaluation; a reviewer's override is kept beside them in Review.
+type Turn struct {
+	Number     int        `json:"number" bson:"number"`
+	Question   string     `json:"question" bson:"question"`
+	Code       string     `json:"code,omitempty" bson:"code,omitempty"`
+	Answer     *Answer    `json:"answer,omitempty" bson:"answer,omitempty"`
+	Rating     *int       `json:"rating,omitempty" bson:"rating,omitempty"`
+	Feedback   *Feedback  `json:"feedback,omitempty" bson:"feedback,omitempty"`
+	AskedAt    time.Time  `json:"askedAt" bson:"askedAt"`
+	AnsweredAt *time.Time `json:"answeredAt,omitempty" bson:"answeredAt,omitempty"`
+	Model      string     `json:"model,omitempty" bson:"model,omitempty"`
+	Attempts   int        `json:"attempts,omitempty" bson:"attempts,omitempty"`
+	// GraderModel and PromptVersion identify the model and prompt that
+	// produced Rating and Feedback.
+	GraderModel   string      `json:"graderModel,omitempty" bson:"graderModel,omitempty"`
+	PromptVersion string      `json:"promptVersion,omitempty" bson:"promptVersion,omitempty"`
+	Review        *TurnReview `json:"review,omitempty" bson:"review,omitempty"`
+	// Difficulty is the level the question was asked at, and Ability the
+	// session's ability estimate after Rating.
+	Difficulty int      `json:"difficulty,omitempty" bson:"difficulty,omitempty"`
+	Ability    *float64 `json:"ability,omitempty" bson:"ability,omitempty"`
+	// RequestKey is the Idempotency-Key of the request that asked this
+	// question, used to replay the response to a retried request.
+	RequestKey string `json:"-" bson:"requestKey,omitempty"`
+}
+
+// IsAnswered reports whether the candidate has answered the turn. Turns
+// migrated from before answers were stored only carry a rating.
+func (t *Turn) IsAnswered() bool {
+	return t.Answer != nil || t.Rating != nil
+}
+
+// EffectiveRating returns the reviewer's rating if the turn was reviewed, and
+// the AI rating otherwise.
+func (t *Turn) EffectiveRating() *int {
+	if t.Review != nil {
+		return &t.Review.Rating
+	}
+	return t.Rating
+}
+
+// EffectiveFeedback returns the reviewer's feedback if they gave any, and the
+// AI feedback otherwise.
+func (t *Turn) EffectiveFeedback() *Feedback {
+	if t.Review != nil && t.Review.Feedback != nil {
+		return t.Review.Feedback
+	}
+	return t.Feedback
+}
+
+// Question holds every turn of a session's interview.
 type Question struct {
 	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
 	SessionId primitive.ObjectID `json:"sessionid" bson:"sessionid"`
-	Question  []string           `json:"question" bson:"question"`
-	Rating    []string           `json:"rating" bson:"rating"`
-	Review    []string           `json:"review" bson:"review"`
+	OrgID     primitive.ObjectID `json:"orgID,omitempty" bson:"orgID,omitempty"`
+	Turns     []Turn             `json:"turns" bson:"turns"`
 	CreatedAt time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
 	UpdatedAt time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
 }
+
+// TurnByRequestKey returns the turn asked by the request with the given
+// Idempotency-Key, or nil.
+func (q *Question) TurnByRequestKey(key string) *Turn {
+	if q == nil || key == "" {
+		return nil
+	}
+	for i := range q.Turns {
+		if q.Turns[i].RequestKey == key {
+			return &q.Turns[i]
+		}
+	}
+	return nil
+}
+
+// CurrentTurn returns the last question asked, or nil if there is none.
+func (q *Question) CurrentTurn() *Turn {
+	if q == nil || len(q.Turns) == 0 {
+		return nil
+	}
+	return &q.Turns[len(q.Turns)-1]
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HireRecommendation is the interviewer's overall verdict.
+type HireRecommendation string
+
+const (
+	StrongHire HireRecommendation = "strong-hire"
+	Hire       HireRecommendation = "hire"
+	LeanHire   HireRecommendation = "lean-hire"
+	LeanNoHire HireRecommendation = "lean-no-hire"
+	NoHire     HireRecommendation = "no-hire"
+	// InsufficientData is used when no answer was evaluated.
+	InsufficientData HireRecommendation = "insufficient-data"
+)
+
+// HireRecommendations are the verdicts the model may give.
+var HireRecommendations = []HireRecommendation{StrongHire, Hire, LeanHire, LeanNoHire, NoHire}
+
+// TopicScore is the average rating of the turns about one topic.
+type TopicScore struct {
+	Topic     string  `json:"topic" bson:"topic"`
+	Score     float64 `json:"score" bson:"score"`
+	Questions int     `json:"questions" bson:"questions"`
+}
+
+// Report is the final assessment of an interview. Scores are computed from
+// the turn ratings; the rest is written by the model.
+type Report struct {
+	OverallScore   float64            `json:"overallScore" bson:"overallScore"`
+	TopicScores    []TopicScore       `json:"topicScores" bson:"topicScores"`
+	Strengths      []string           `json:"strengths" bson:"strengths"`
+	Weaknesses     []string           `json:"weaknesses" bson:"weaknesses"`
+	Roadmap        []string           `json:"roadmap" bson:"roadmap"`
+	Recommendation HireRecommendation `json:"recommendation" bson:"recommendation"`
+	Summary        string             `json:"summary" bson:"summary"`
+	// DifficultyTrajectory is the level of every question and how the
+	// ability estimate moved with each rating.
+	DifficultyTrajectory []DifficultyPoint `json:"difficultyTrajectory" bson:"difficultyTrajectory"`
+	FinalAbility         float64           `json:"finalAbility,omitempty" bson:"finalAbility,omitempty"`
+	Model                string            `json:"model,omitempty" bson:"model,omitempty"`
+	GeneratedAt          time.Time         `json:"generatedAt" bson:"generatedAt"`
+}
+
+// TurnTopic assigns a topic to one turn.
+type TurnTopic struct {
+	Turn  int    `json:"turn"`
+	Topic string `json:"topic"`
+}
+
+// ReportAnalysis is the typed reply of the model asked to write the report.
+type ReportAnalysis struct {
+	TurnTopics     []TurnTopic        `json:"turnTopics"`
+	Strengths      []string           `json:"strengths"`
+	Weaknesses     []string           `json:"weaknesses"`
+	Roadmap        []string           `json:"roadmap"`
+	Recommendation HireRecommendation `json:"recommendation"`
+	Summary        string             `json:"summary"`
+}
+
+// Validate reports every problem with the analysis.
+func (a *ReportAnalysis) Validate() error {
+	var problems []string
+
+	if !slices.Contains(HireRecommendations, a.Recommendation) {
+		problems = append(problems, fmt.Sprintf("recommendation %q is not one of the allowed values", a.Recommendation))
+	}
+
+	if strings.TrimSpace(a.Summary) == "" {
+		problems = append(problems, "summary is missing")
+	}
+	if len(a.Strengths) == 0 && len(a.Weaknesses) == 0 {
+		problems = append(problems, "strengths and weaknesses are both empty")
+	}
+	if len(a.Roadmap) == 0 {
+		problems = append(problems, "roadmap is empty")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("malformed report: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
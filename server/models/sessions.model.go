@@ -4,7 +4,6 @@ import (
 	"errors"
 	"strings"
 	"time"
-	
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -23,24 +22,27 @@ type Project struct {
 	Description string   `json:"description" bson:"description"`
 }
 
-type AllowedInterviewStatus string
-
-const (
-	NotStarted         AllowedInterviewStatus = "not-started"
-	WaitingForAnswer   AllowedInterviewStatus = "waiting-for-answer"
-	Ended   AllowedInterviewStatus = "ended"
-)
-
 type Session struct {
 	ID              primitive.ObjectID     `json:"_id,omitempty" bson:"_id,omitempty"`
 	UserType        UserType               `json:"userType" bson:"userType"`
 	UserID          primitive.ObjectID     `json:"userID,omitempty" bson:"userID,omitempty"`
+	OrgID           primitive.ObjectID     `json:"orgID,omitempty" bson:"orgID,omitempty"`
 	Name            string                 `json:"name,omitempty" bson:"name,omitempty"`
 	Experience      string                 `json:"experience,omitempty" bson:"experience,omitempty"`
 	TechStacks      []string               `json:"techStacks" bson:"techStacks"`
 	Projects        []Project              `json:"projects,omitempty" bson:"projects,omitempty"`
 	InterviewStatus AllowedInterviewStatus `json:"interviewstatus,omitempty" bson:"interviewstatus,omitempty"`
 	HasExpired      bool                   `json:"hasExpired,omitempty" bson:"hasExpired,omitempty"`
+	ExpiredAt       *time.Time             `json:"expiredAt,omitempty" bson:"expiredAt,omitempty"`
+	PausedAt        *time.Time             `json:"pausedAt,omitempty" bson:"pausedAt,omitempty"`           // set while paused
+	PausedSeconds   int64                  `json:"pausedSeconds,omitempty" bson:"pausedSeconds,omitempty"` // time spent in earlier pauses
+	Usage           UsageTotals            `json:"usage" bson:"usage"`
+	Report          *Report                `json:"report,omitempty" bson:"report,omitempty"`
+	GuestTokenHash  string                 `json:"-" bson:"guestTokenHash,omitempty"`
+	ClaimedAt       *time.Time             `json:"claimedAt,omitempty" bson:"claimedAt,omitempty"`
+	Model           string                 `json:"model,omitempty" bson:"model,omitempty"`     // organization's model, if any
+	Persona         string                 `json:"-" bson:"persona,omitempty"`                 // organization's interviewer persona, if any
+	Ability         float64                `json:"ability,omitempty" bson:"ability,omitempty"` // running ability estimate, see UpdateAbility
 	CreatedAt       time.Time              `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
 }
@@ -51,6 +53,9 @@ func (s *Session) ValidateAndInitialize() error {
 		return errors.New("ID should not be provided, it will be generated by the database")
 	}
 
+	// Drop whatever the client sent for server-owned fields
+	s.resetServerFields()
+
 	// Set default experience
 	if s.Experience == "" {
 		s.Experience = "Fresher"
@@ -86,22 +91,43 @@ func (s *Session) ValidateAndInitialize() error {
 
 	}
 
+	// Every interview starts at the beginning of the state machine
 	if s.InterviewStatus == "" {
 		s.InterviewStatus = NotStarted
-	} else if s.InterviewStatus != NotStarted && s.InterviewStatus != WaitingForAnswer && s.InterviewStatus != Ended {
-		return errors.New("invalid interviewStatus")
+	} else if s.InterviewStatus != NotStarted {
+		return errors.New("interviewStatus must be 'not-started' for a new session")
 	}
 
-	// Set HasExpired to false initially
-	s.HasExpired = false
+	// Ability starts from the stated experience until answers are rated
+	s.Ability = InitialAbility(s.Experience)
 
-	// Set createdAt if not already set
-	if s.CreatedAt.IsZero() {
-		s.CreatedAt = time.Now()
-	}
-
-	// Always set updatedAt to the current time
-	s.UpdatedAt = time.Now()
+	// The clock starts now: quotas, expiry and progress all count from
+	// createdAt
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
 
 	return nil
 }
+
+// resetServerFields clears the fields only the server may set on a new
+// session.
+func (s *Session) resetServerFields() {
+	// Guest tokens and the tenant are set by the server
+	s.GuestTokenHash = ""
+	s.ClaimedAt = nil
+	s.OrgID = primitive.NilObjectID
+	s.Model = ""
+	s.Persona = ""
+
+	// A new session has not expired, been paused, used tokens or been
+	// reported on
+	s.HasExpired = false
+	s.ExpiredAt = nil
+	s.PausedAt = nil
+	s.PausedSeconds = 0
+	s.Usage = UsageTotals{}
+	s.Report = nil
+	s.Ability = 0
+	s.CreatedAt = time.Time{}
+	s.UpdatedAt = time.Time{}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAndInitializeResetsServerFields(t *testing.T) {
+	past := time.Now().AddDate(0, 0, -30)
+	session := Session{
+		UserType:      Guest,
+		TechStacks:    []string{"Go"},
+		HasExpired:    true,
+		ExpiredAt:     &past,
+		PausedAt:      &past,
+		PausedSeconds: 3600,
+		Usage:         UsageTotals{TotalTokens: 10},
+		Report:        &Report{OverallScore: 10},
+		Ability:       5,
+		CreatedAt:     past,
+		UpdatedAt:     past,
+	}
+
+	before := time.Now()
+	if err := session.ValidateAndInitialize(); err != nil {
+		t.Fatalf("ValidateAndInitialize: %v", err)
+	}
+
+	if session.CreatedAt.Before(before) || !session.UpdatedAt.Equal(session.CreatedAt) {
+		t.Errorf("createdAt %v, updatedAt %v: want both set to now", session.CreatedAt, session.UpdatedAt)
+	}
+	if session.HasExpired || session.ExpiredAt != nil {
+		t.Errorf("expiry was kept: hasExpired %v, expiredAt %v", session.HasExpired, session.ExpiredAt)
+	}
+	if session.PausedAt != nil || session.PausedSeconds != 0 {
+		t.Errorf("pause was kept: pausedAt %v, pausedSeconds %d", session.PausedAt, session.PausedSeconds)
+	}
+	if session.Usage != (UsageTotals{}) || session.Report != nil {
+		t.Errorf("usage %+v and report %+v were kept", session.Usage, session.Report)
+	}
+	if session.Ability != InitialAbility("Fresher") {
+		t.Errorf("ability %v: want the initial ability %v", session.Ability, InitialAbility("Fresher"))
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UsageTotals is a running total of tokens and estimated cost.
+type UsageTotals struct {
+	PromptTokens    int     `json:"promptTokens" bson:"promptTokens"`
+	CandidateTokens int     `json:"candidateTokens" bson:"candidateTokens"`
+	TotalTokens     int     `json:"totalTokens" bson:"totalTokens"`
+	EstimatedCost   float64 `json:"estimatedCost" bson:"estimatedCost"`
+	Calls           int     `json:"calls" bson:"calls"`
+}
+
+// UsageRecord is stored for every LLM call. SessionId is empty for calls made
+// before a session exists, such as resume parsing.
+type UsageRecord struct {
+	ID              primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	SessionId       primitive.ObjectID `json:"sessionid,omitempty" bson:"sessionid,omitempty"`
+	OrgID           primitive.ObjectID `json:"orgID,omitempty" bson:"orgID,omitempty"`
+	Operation       string             `json:"operation" bson:"operation"`
+	Model           string             `json:"model" bson:"model"`
+	PromptTokens    int                `json:"promptTokens" bson:"promptTokens"`
+	CandidateTokens int                `json:"candidateTokens" bson:"candidateTokens"`
+	TotalTokens     int                `json:"totalTokens" bson:"totalTokens"`
+	EstimatedCost   float64            `json:"estimatedCost" bson:"estimatedCost"`
+	// Day is the UTC date of the call (YYYY-MM-DD), used for daily totals.
+	Day       string    `json:"day" bson:"day"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// DailyUsage is the total usage across all sessions for one UTC day.
+type DailyUsage struct {
+	Day         string `json:"day" bson:"_id"`
+	UsageTotals `bson:",inline"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Password length bounds at signup. bcrypt ignores anything past 72 bytes.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// Role decides what an account may do beyond its own interviews.
+type Role string
+
+const (
+	// RoleCandidate takes interviews and only sees their own sessions.
+	RoleCandidate Role = "candidate"
+	// RoleReviewer can also read, but not change, every session of their
+	// organization and leave notes on them.
+	RoleReviewer Role = "reviewer"
+	// RoleAdmin is a reviewer who can also change the roles of the
+	// organization's accounts.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleCandidate, RoleReviewer, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// CanReview reports whether the role may read other users' sessions.
+func (r Role) CanReview() bool {
+	return r == RoleReviewer || r == RoleAdmin
+}
+
+// UserAccount is a registered account. The password is only ever stored hashed.
+type UserAccount struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	OrgID        primitive.ObjectID `json:"orgID,omitempty" bson:"orgID,omitempty"`
+	Name         string             `json:"name" bson:"name"`
+	Email        string             `json:"email" bson:"email"`
+	Role         Role               `json:"role" bson:"role,omitempty"`
+	PasswordHash string             `json:"-" bson:"passwordHash"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// RoleOrDefault returns the account's role. Accounts created before roles
+// existed are candidates.
+func (u *UserAccount) RoleOrDefault() Role {
+	if u.Role == "" {
+		return RoleCandidate
+	}
+	return u.Role
+}
+
+// Credentials is the body of the signup and login requests. Name is only
+// used at signup.
+type Credentials struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// NormalizeEmail returns email in the form it is stored and looked up in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateSignup normalizes the credentials and checks they can create an
+// account.
+func (c *Credentials) ValidateSignup() error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Email = NormalizeEmail(c.Email)
+
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if _, err := mail.ParseAddress(c.Email); err != nil {
+		return errors.New("a valid email is required")
+	}
+	if len(c.Password) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	if len(c.Password) > MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", MaxPasswordLength)
+	}
+	return nil
+}
@@ -7,18 +7,52 @@ import (
 
 func Router() *mux.Router {
 	router := mux.NewRouter()
+	router.Use(controllers.Authenticate, controllers.ResolveTenant)
 
 	// Root health check for Render
 	router.HandleFunc("/", controllers.HealthCheck).Methods("GET")
 	router.HandleFunc("/health", controllers.HealthCheck).Methods("GET")
-	
+
+	// Auth routes
+	router.HandleFunc("/api/v1/auth/signup", controllers.Signup).Methods("POST")
+	router.HandleFunc("/api/v1/auth/login", controllers.Login).Methods("POST")
+	router.HandleFunc("/api/v1/auth/me", controllers.GetCurrentUser).Methods("GET")
+	router.HandleFunc("/api/v1/users", controllers.ListUsers).Methods("GET")
+	router.HandleFunc("/api/v1/users/{userId}/role", controllers.SetUserRole).Methods("PUT")
+
+	// Organization routes
+	router.HandleFunc("/api/v1/orgs", controllers.CreateOrg).Methods("POST")
+	router.HandleFunc("/api/v1/orgs", controllers.ListOrgs).Methods("GET")
+	router.HandleFunc("/api/v1/orgs/{orgId}/keys", controllers.IssueOrgAPIKey).Methods("POST")
+	router.HandleFunc("/api/v1/org", controllers.GetOrg).Methods("GET")
+	router.HandleFunc("/api/v1/org/config", controllers.UpdateOrgConfig).Methods("PUT")
+	router.HandleFunc("/api/v1/org/keys", controllers.CreateAPIKey).Methods("POST")
+	router.HandleFunc("/api/v1/org/keys/{keyId}", controllers.RevokeAPIKey).Methods("DELETE")
+
 	// Session routes
 	router.HandleFunc("/api/v1/session", controllers.CreateSession).Methods("POST")
+	router.HandleFunc("/api/v1/session/{sessionId}", controllers.GetSessionDetails).Methods("GET")
+	router.HandleFunc("/api/v1/sessions", controllers.ListSessions).Methods("GET")
 	router.HandleFunc("/api/v1/ask-to-gemini/{sessionId}", controllers.AskToGemini).Methods("POST")
+	router.HandleFunc("/api/v1/ask-to-gemini/{sessionId}/stream", controllers.AskToGeminiStream).Methods("POST")
 	router.HandleFunc("/api/v1/end/{sessionId}", controllers.EndSession).Methods("POST")
+	router.HandleFunc("/api/v1/session/{sessionId}/pause", controllers.PauseSession).Methods("POST")
+	router.HandleFunc("/api/v1/session/{sessionId}/resume", controllers.ResumeSession).Methods("POST")
+	router.HandleFunc("/api/v1/session/{sessionId}/claim", controllers.ClaimSession).Methods("POST")
+	router.HandleFunc("/api/v1/session/{sessionId}/audit", controllers.GetSessionAudit).Methods("GET")
+	router.HandleFunc("/api/v1/session/{sessionId}/notes", controllers.GetSessionNotes).Methods("GET")
+	router.HandleFunc("/api/v1/session/{sessionId}/notes", controllers.AddSessionNote).Methods("POST")
+	router.HandleFunc("/api/v1/session/{sessionId}/turns/{turn}/review", controllers.ReviewTurn).Methods("PUT")
+	router.HandleFunc("/api/v1/analytics/agreement", controllers.GetRatingAgreement).Methods("GET")
+	router.HandleFunc("/api/v1/progress", controllers.GetProgress).Methods("GET")
+	router.HandleFunc("/api/v1/progress/timeseries", controllers.GetProgressTimeSeries).Methods("GET")
 	router.HandleFunc("/api/v1/health", controllers.HealthCheck).Methods("GET")
 
+	// Usage routes
+	router.HandleFunc("/api/v1/usage/session/{sessionId}", controllers.GetSessionUsage).Methods("GET")
+	router.HandleFunc("/api/v1/usage/daily", controllers.GetUsageByDay).Methods("GET")
+
 	router.HandleFunc("/api/v1/upload", controllers.UploadResume).Methods("POST", "OPTIONS")
 
 	return router
-}
\ No newline at end of file
+}
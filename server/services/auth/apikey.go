@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// apiKeyPrefix starts every organization API key, so leaked keys are easy
+// to recognize.
+const apiKeyPrefix = "pk_"
+
+// NewAPIKey returns a new organization API key, the public ID it is listed
+// and revoked by, and the hash to store in its place.
+func NewAPIKey() (key string, id string, hash string, err error) {
+	idBytes := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %v", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %v", err)
+	}
+
+	id = hex.EncodeToString(idBytes)
+	key = apiKeyPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, id, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of an API key. Keys are long random
+// secrets, so a plain SHA-256 is enough.
+func HashAPIKey(key string) string {
+	return hashSecret(key)
+}
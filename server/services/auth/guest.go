@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewGuestToken returns a random capability token for a guest session and
+// the hash to store in its place.
+func NewGuestToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate guest token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashGuestToken(token), nil
+}
+
+// HashGuestToken returns the stored form of a guest token.
+func HashGuestToken(token string) string {
+	return hashSecret(token)
+}
+
+// hashSecret hashes a random server-issued secret for storage.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckGuestToken reports whether token hashes to hash.
+func CheckGuestToken(hash string, token string) bool {
+	if hash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashGuestToken(token))) == 1
+}
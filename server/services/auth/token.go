@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTokenTTL is how long an access token is valid without JWT_TTL.
+const DefaultTokenTTL = 24 * time.Hour
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed or were not
+	// signed with our secret.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for correctly signed tokens past their
+	// expiry.
+	ErrTokenExpired = errors.New("token has expired")
+)
+
+// Claims are the JWT claims of an access token. Subject is the user ID and
+// Org the ID of the organization the user belongs to, if any.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Org       string `json:"org,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// tokenHeader is the only JOSE header we issue or accept.
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// TokenIssuer signs and verifies HS256 JWT access tokens.
+type TokenIssuer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewTokenIssuer(secret []byte, ttl time.Duration) *TokenIssuer {
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	return &TokenIssuer{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// NewTokenIssuerFromEnv signs tokens with JWT_SECRET, valid for JWT_TTL.
+// JWT_SECRET is required unless JWT_EPHEMERAL_SECRET=true, for local
+// development, where a random secret is used: tokens then do not survive a
+// restart and are not accepted by other replicas.
+func NewTokenIssuerFromEnv() (*TokenIssuer, error) {
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	if len(secret) == 0 {
+		if os.Getenv("JWT_EPHEMERAL_SECRET") != "true" {
+			return nil, fmt.Errorf("JWT_SECRET is not set; set JWT_EPHEMERAL_SECRET=true to use a random per-process secret in development")
+		}
+		log.Println("Warning: JWT_SECRET not set. Using a random secret, tokens will be invalidated on restart.")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %v", err)
+		}
+	} else if len(secret) < 32 {
+		log.Println("Warning: JWT_SECRET is shorter than 32 bytes.")
+	}
+
+	ttl := DefaultTokenTTL
+	if v := os.Getenv("JWT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid JWT_TTL %q", v)
+		}
+		ttl = d
+	}
+
+	return NewTokenIssuer(secret, ttl), nil
+}
+
+// Issue returns a signed token carrying claims, stamped with the issue and
+// expiry times, and when it expires.
+func (t *TokenIssuer) Issue(claims Claims) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.ttl)
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = expiresAt.Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode claims: %v", err)
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + t.sign(unsigned), expiresAt, nil
+}
+
+// Verify checks the signature and expiry of token and returns its claims.
+func (t *TokenIssuer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+
+	unsigned := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(t.sign(unsigned))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if t.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+func (t *TokenIssuer) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
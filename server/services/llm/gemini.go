@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+const DefaultGeminiModel = "gemini-2.5-flash"
+
+// GeminiProvider talks to the Google Gemini API.
+type GeminiProvider struct {
+	client    *genai.Client
+	model     *genai.GenerativeModel
+	modelName string
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+func NewGeminiProvider(ctx context.Context, apiKey string, modelName string) (*GeminiProvider, error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+
+	return &GeminiProvider{
+		client:    client,
+		model:     client.GenerativeModel(modelName),
+		modelName: modelName,
+	}, nil
+}
+
+func (g *GeminiProvider) Name() string {
+	return "gemini/" + g.modelName
+}
+
+func (g *GeminiProvider) GenerateText(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	return g.generate(ctx, applyOptions(opts), genai.Text(prompt))
+}
+
+func (g *GeminiProvider) GenerateWithBlobs(ctx context.Context, prompt string, blobs []Blob, opts ...Option) (*Response, error) {
+	parts := []genai.Part{genai.Text(prompt)}
+	for _, b := range blobs {
+		parts = append(parts, genai.Blob{MIMEType: b.MIMEType, Data: b.Data})
+	}
+	return g.generate(ctx, applyOptions(opts), parts...)
+}
+
+func (g *GeminiProvider) GenerateTextStream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) (*Response, error) {
+	o := applyOptions(opts)
+	iter := g.modelFor(o).GenerateContentStream(ctx, genai.Text(prompt))
+
+	var sb strings.Builder
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Gemini stream failed: %w", err)
+		}
+
+		chunk, err := geminiText(resp)
+		if err != nil {
+			// Some stream messages only carry metadata.
+			continue
+		}
+		sb.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	if sb.Len() == 0 {
+		return nil, fmt.Errorf("empty response from Gemini")
+	}
+
+	usage := geminiUsage(iter.MergedResponse())
+	g.mu.Lock()
+	g.usage.Add(usage)
+	g.mu.Unlock()
+
+	return &Response{Text: sb.String(), Model: g.modelNameFor(o), Usage: usage}, nil
+}
+
+func (g *GeminiProvider) Usage() Usage {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.usage
+}
+
+// Close releases the underlying Gemini client.
+func (g *GeminiProvider) Close() error {
+	return g.client.Close()
+}
+
+// modelFor returns the shared model, or a copy configured for another model
+// or for JSON output when a schema is requested.
+func (g *GeminiProvider) modelFor(o GenerateOptions) *genai.GenerativeModel {
+	if o.Schema == nil && o.Model == "" {
+		return g.model
+	}
+
+	m := g.client.GenerativeModel(g.modelNameFor(o))
+	if o.Schema != nil {
+		m.ResponseMIMEType = "application/json"
+		m.ResponseSchema = toGeminiSchema(o.Schema)
+	}
+	return m
+}
+
+func (g *GeminiProvider) modelNameFor(o GenerateOptions) string {
+	if o.Model != "" {
+		return o.Model
+	}
+	return g.modelName
+}
+
+func (g *GeminiProvider) generate(ctx context.Context, o GenerateOptions, parts ...genai.Part) (*Response, error) {
+	resp, err := g.modelFor(o).GenerateContent(ctx, parts...)
+	if err != nil {
+		return nil, fmt.Errorf("Gemini request failed: %w", err)
+	}
+
+	text, err := geminiText(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := geminiUsage(resp)
+	g.mu.Lock()
+	g.usage.Add(usage)
+	g.mu.Unlock()
+
+	return &Response{Text: text, Model: g.modelNameFor(o), Usage: usage}, nil
+}
+
+// geminiText joins the text parts of the first candidate.
+func geminiText(resp *genai.GenerateContentResponse) (string, error) {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil ||
+		len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("empty response from Gemini")
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("unexpected Gemini response format")
+	}
+	return sb.String(), nil
+}
+
+func geminiUsage(resp *genai.GenerateContentResponse) Usage {
+	if resp == nil || resp.UsageMetadata == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:    int(resp.UsageMetadata.PromptTokenCount),
+		CandidateTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+		TotalTokens:     int(resp.UsageMetadata.TotalTokenCount),
+	}
+}
+
+func toGeminiSchema(s *Schema) *genai.Schema {
+	if s == nil {
+		return nil
+	}
+
+	out := &genai.Schema{
+		Description: s.Description,
+		Enum:        s.Enum,
+		Items:       toGeminiSchema(s.Items),
+		Required:    s.Required,
+		Nullable:    s.Nullable,
+	}
+
+	switch s.Type {
+	case TypeObject:
+		out.Type = genai.TypeObject
+	case TypeArray:
+		out.Type = genai.TypeArray
+	case TypeString:
+		out.Type = genai.TypeString
+	case TypeInteger:
+		out.Type = genai.TypeInteger
+	case TypeNumber:
+		out.Type = genai.TypeNumber
+	case TypeBoolean:
+		out.Type = genai.TypeBoolean
+	}
+
+	if len(s.Properties) > 0 {
+		out.Properties = make(map[string]*genai.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = toGeminiSchema(prop)
+		}
+	}
+	return out
+}
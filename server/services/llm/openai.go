@@ -0,0 +1,366 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOllamaBaseURL = "http://localhost:11434/v1"
+	DefaultOllamaModel   = "llama3.1"
+)
+
+// OpenAIConfig configures a backend speaking the OpenAI chat completions API.
+// This covers OpenAI itself as well as Ollama, vLLM and llama.cpp server.
+type OpenAIConfig struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+
+	// SupportsFileInput sends PDFs as file content parts. When false the PDF
+	// text is extracted locally and inlined into the prompt instead.
+	SupportsFileInput bool
+
+	HTTPClient *http.Client
+}
+
+// OpenAIProvider talks to an OpenAI-compatible chat completions endpoint.
+type OpenAIProvider struct {
+	cfg  OpenAIConfig
+	http *http.Client
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// StatusError is returned when the backend answers with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("LLM backend returned status %d: %s", e.StatusCode, e.Body)
+}
+
+func NewOpenAIProvider(cfg OpenAIConfig) (*OpenAIProvider, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("a model name is required for the OpenAI-compatible provider")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultOpenAIBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+
+	// No client timeout: it would cut off streams that are still producing
+	// output. Calls are bounded by their context, which the resilient
+	// provider gives a per-operation deadline.
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	return &OpenAIProvider{cfg: cfg, http: httpClient}, nil
+}
+
+func (o *OpenAIProvider) Name() string {
+	return "openai/" + o.cfg.Model
+}
+
+func (o *OpenAIProvider) GenerateText(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	return o.chat(ctx, prompt, applyOptions(opts))
+}
+
+func (o *OpenAIProvider) GenerateWithBlobs(ctx context.Context, prompt string, blobs []Blob, opts ...Option) (*Response, error) {
+	parts := []chatPart{}
+	var inlined strings.Builder
+	inlined.WriteString(prompt)
+
+	for _, b := range blobs {
+		switch {
+		case b.MIMEType == "application/pdf" && !o.cfg.SupportsFileInput:
+			text, err := ExtractPDFText(b.Data)
+			if err != nil {
+				return nil, err
+			}
+			inlined.WriteString("\n\n<Document>\n")
+			inlined.WriteString(text)
+			inlined.WriteString("\n</Document>\n")
+		case b.MIMEType == "application/pdf":
+			parts = append(parts, chatPart{
+				Type: "file",
+				File: &chatFile{
+					Filename: "document.pdf",
+					FileData: dataURL(b),
+				},
+			})
+		case strings.HasPrefix(b.MIMEType, "image/"):
+			parts = append(parts, chatPart{
+				Type:     "image_url",
+				ImageURL: &chatImageURL{URL: dataURL(b)},
+			})
+		case strings.HasPrefix(b.MIMEType, "text/"):
+			inlined.WriteString("\n\n<Document>\n")
+			inlined.Write(b.Data)
+			inlined.WriteString("\n</Document>\n")
+		default:
+			return nil, fmt.Errorf("unsupported attachment type %q", b.MIMEType)
+		}
+	}
+
+	if len(parts) == 0 {
+		return o.chat(ctx, inlined.String(), applyOptions(opts))
+	}
+
+	parts = append([]chatPart{{Type: "text", Text: inlined.String()}}, parts...)
+	return o.chat(ctx, parts, applyOptions(opts))
+}
+
+func (o *OpenAIProvider) Usage() Usage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.usage
+}
+
+type chatFile struct {
+	Filename string `json:"filename"`
+	FileData string `json:"file_data"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
+
+type chatPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	File     *chatFile     `json:"file,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+}
+
+type chatMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatJSONSchema struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+}
+
+type chatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *chatJSONSchema `json:"json_schema,omitempty"`
+}
+
+type chatRequest struct {
+	Model          string              `json:"model"`
+	Messages       []chatMessage       `json:"messages"`
+	Stream         bool                `json:"stream,omitempty"`
+	StreamOptions  *chatStreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *chatResponseFormat `json:"response_format,omitempty"`
+}
+
+func newChatRequest(model string, content interface{}, o GenerateOptions) chatRequest {
+	req := chatRequest{
+		Model:    model,
+		Messages: []chatMessage{{Role: "user", Content: content}},
+	}
+	if o.Model != "" {
+		req.Model = o.Model
+	}
+	if o.Schema != nil {
+		req.ResponseFormat = &chatResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &chatJSONSchema{Name: "response", Schema: o.Schema},
+		}
+	}
+	return req
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *chatUsage) toUsage() Usage {
+	if u == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:    u.PromptTokens,
+		CandidateTokens: u.CompletionTokens,
+		TotalTokens:     u.TotalTokens,
+	}
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+}
+
+type chatStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+}
+
+// chat sends a single user message whose content is either a string or a
+// slice of content parts.
+func (o *OpenAIProvider) chat(ctx context.Context, content interface{}, opts GenerateOptions) (*Response, error) {
+	reqBody := newChatRequest(o.cfg.Model, content, opts)
+
+	var parsed chatResponse
+	if err := o.post(ctx, "/chat/completions", reqBody, &parsed); err != nil {
+		return nil, err
+	}
+
+	if len(parsed.Choices) == 0 || parsed.Choices[0].Message.Content == "" {
+		return nil, fmt.Errorf("empty response from LLM backend")
+	}
+
+	usage := parsed.Usage.toUsage()
+	o.mu.Lock()
+	o.usage.Add(usage)
+	o.mu.Unlock()
+
+	model := parsed.Model
+	if model == "" {
+		model = reqBody.Model
+	}
+
+	return &Response{Text: parsed.Choices[0].Message.Content, Model: model, Usage: usage}, nil
+}
+
+func (o *OpenAIProvider) GenerateTextStream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) (*Response, error) {
+	reqBody := newChatRequest(o.cfg.Model, prompt, applyOptions(opts))
+	reqBody.Stream = true
+	reqBody.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+
+	resp, err := o.do(ctx, "/chat/completions", reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	var usage Usage
+	model := reqBody.Model
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk chatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("invalid stream chunk from LLM backend: %v", err)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage.toUsage()
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		text := chunk.Choices[0].Delta.Content
+		sb.WriteString(text)
+		if err := onChunk(text); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("LLM stream failed: %w", err)
+	}
+
+	if sb.Len() == 0 {
+		return nil, fmt.Errorf("empty response from LLM backend")
+	}
+
+	o.mu.Lock()
+	o.usage.Add(usage)
+	o.mu.Unlock()
+
+	return &Response{Text: sb.String(), Model: model, Usage: usage}, nil
+}
+
+func (o *OpenAIProvider) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	resp, err := o.do(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from LLM backend: %v", err)
+	}
+	return nil
+}
+
+// do sends the request and returns the response once the status is known to
+// be successful. The caller must close the body.
+func (o *OpenAIProvider) do(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode LLM request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.cfg.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build LLM request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.cfg.APIKey)
+	}
+
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+
+	return resp, nil
+}
+
+func dataURL(b Blob) string {
+	return "data:" + b.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(b.Data)
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestOpenAIStreamIsBoundedByContext checks a stream runs for as long as its
+// context allows, and no longer.
+func TestOpenAIStreamIsBoundedByContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for {
+			fmt.Fprint(w, "data: {\"choices\": [{\"delta\": {\"content\": \"a\"}}]}\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewOpenAIProvider(OpenAIConfig{BaseURL: server.URL, Model: "test"})
+	if err != nil {
+		t.Fatalf("NewOpenAIProvider: %v", err)
+	}
+	if provider.http.Timeout != 0 {
+		t.Errorf("default client has a %v timeout, which would cut off long streams", provider.http.Timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	chunks := 0
+	_, err = provider.GenerateTextStream(ctx, "prompt", func(string) error {
+		chunks++
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the context deadline", err)
+	}
+	if chunks == 0 {
+		t.Error("no chunks were streamed before the deadline")
+	}
+}
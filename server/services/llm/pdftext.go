@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// ExtractPDFText returns the plain text of a PDF for backends that cannot
+// accept file input.
+func ExtractPDFText(data []byte) (text string, err error) {
+	// The PDF reader panics on some malformed files.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to read PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF: %v", err)
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("failed to extract PDF text: %v", err)
+	}
+
+	raw, err := io.ReadAll(plain)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract PDF text: %v", err)
+	}
+
+	text = strings.TrimSpace(string(raw))
+	if text == "" {
+		return "", fmt.Errorf("PDF contains no extractable text")
+	}
+	return text, nil
+}
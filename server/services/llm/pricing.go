@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Pricing is the price in USD per million prompt and candidate tokens.
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// knownPricing holds list prices for the models we ship defaults for.
+// Self-hosted models are free unless LLM_PRICE_* says otherwise.
+var knownPricing = map[string]Pricing{
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-flash-lite": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"gpt-4o-mini":           {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-4o":                {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+}
+
+// PricingFor returns the pricing for model. LLM_PRICE_INPUT_PER_1M and
+// LLM_PRICE_OUTPUT_PER_1M override the built-in table.
+func PricingFor(model string) Pricing {
+	p := knownPricing[strings.ToLower(model)]
+
+	if v, err := strconv.ParseFloat(os.Getenv("LLM_PRICE_INPUT_PER_1M"), 64); err == nil {
+		p.InputPerMillion = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("LLM_PRICE_OUTPUT_PER_1M"), 64); err == nil {
+		p.OutputPerMillion = v
+	}
+	return p
+}
+
+// Cost estimates the USD cost of u.
+func (p Pricing) Cost(u Usage) float64 {
+	return (float64(u.PromptTokens)*p.InputPerMillion + float64(u.CandidateTokens)*p.OutputPerMillion) / 1e6
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Usage holds the token counts reported for one or more generation calls.
+type Usage struct {
+	PromptTokens    int `json:"promptTokens" bson:"promptTokens"`
+	CandidateTokens int `json:"candidateTokens" bson:"candidateTokens"`
+	TotalTokens     int `json:"totalTokens" bson:"totalTokens"`
+}
+
+// Add accumulates another usage report into u.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CandidateTokens += other.CandidateTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// Blob is a binary attachment (e.g. a resume PDF) sent alongside a prompt.
+type Blob struct {
+	MIMEType string
+	Data     []byte
+}
+
+// Response is the text produced by a single generation call.
+type Response struct {
+	Text  string
+	Model string
+	Usage Usage
+}
+
+// LLMProvider is the backend every interview and resume call goes through.
+type LLMProvider interface {
+	// Name identifies the backend and model, e.g. "gemini/gemini-2.5-flash".
+	Name() string
+
+	// GenerateText sends a plain text prompt.
+	GenerateText(ctx context.Context, prompt string, opts ...Option) (*Response, error)
+
+	// GenerateWithBlobs sends a prompt together with binary attachments.
+	GenerateWithBlobs(ctx context.Context, prompt string, blobs []Blob, opts ...Option) (*Response, error)
+
+	// GenerateTextStream sends a plain text prompt and calls onChunk with each
+	// piece of text as it arrives. The returned Response holds the full text.
+	// Returning an error from onChunk aborts the stream.
+	GenerateTextStream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) (*Response, error)
+
+	// Usage reports the tokens consumed by this provider since startup.
+	Usage() Usage
+}
+
+// NewFromEnv builds the provider selected by LLM_PROVIDER.
+//
+// Supported values are "gemini", "openai", "ollama" and "scripted" (or
+// "fake"). When LLM_PROVIDER is unset the Gemini backend is used, which needs
+// GEMINI_API_KEY; the scripted fake is only used when asked for, so a missing
+// key never silently serves canned interviews. LLM_MODEL overrides the model
+// for every backend.
+func NewFromEnv(ctx context.Context) (LLMProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER")))
+	modelName := os.Getenv("LLM_MODEL")
+	apiKey := os.Getenv("GEMINI_API_KEY")
+
+	if name == "" {
+		if apiKey == "" {
+			return nil, fmt.Errorf("neither LLM_PROVIDER nor GEMINI_API_KEY is set; set LLM_PROVIDER=scripted to run with the scripted fake provider")
+		}
+		name = "gemini"
+	}
+
+	switch name {
+	case "gemini":
+		if apiKey == "" {
+			return nil, fmt.Errorf("LLM_PROVIDER is gemini but GEMINI_API_KEY is not set")
+		}
+		if modelName == "" {
+			modelName = DefaultGeminiModel
+		}
+		return NewGeminiProvider(ctx, apiKey, modelName)
+	case "openai", "ollama":
+		cfg := OpenAIConfig{
+			BaseURL:           os.Getenv("LLM_BASE_URL"),
+			APIKey:            os.Getenv("LLM_API_KEY"),
+			Model:             modelName,
+			SupportsFileInput: os.Getenv("LLM_FILE_INPUT") == "true",
+		}
+		if name == "ollama" {
+			if cfg.BaseURL == "" {
+				cfg.BaseURL = DefaultOllamaBaseURL
+			}
+			if cfg.Model == "" {
+				cfg.Model = DefaultOllamaModel
+			}
+		}
+		return NewOpenAIProvider(cfg)
+	case "fake", "scripted":
+		return NewScriptedProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM_PROVIDER %q", name)
+	}
+}
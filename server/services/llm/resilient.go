@@ -0,0 +1,356 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/grpc/codes"
+)
+
+// Operation names a kind of LLM call so it can get its own deadline.
+type Operation string
+
+const (
+	OpInterview Operation = "interview"
+	OpResume    Operation = "resume"
+	// OpRecap welcomes a candidate back to a paused interview.
+	OpRecap Operation = "recap"
+	// OpReport writes the final report when an interview ends.
+	OpReport Operation = "report"
+)
+
+type operationKey struct{}
+
+// WithOperation tags ctx with the operation being performed.
+func WithOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+func operationFrom(ctx context.Context) Operation {
+	op, _ := ctx.Value(operationKey{}).(Operation)
+	return op
+}
+
+type sessionKey struct{}
+
+// WithSession tags ctx with the interview session the call is made for.
+func WithSession(ctx context.Context, sessionId string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionId)
+}
+
+func sessionFrom(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// Policy configures deadlines, retries and the circuit breaker.
+type Policy struct {
+	// Timeouts bounds the whole operation, retries included. DefaultTimeout
+	// applies to operations without an entry.
+	Timeouts       map[Operation]time.Duration
+	DefaultTimeout time.Duration
+
+	// MaxRetries is the number of retries after the first attempt for
+	// retryable errors (429, 5xx, unavailable).
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// The breaker opens after BreakerThreshold consecutive failures and
+	// rejects calls for BreakerCooldown before letting a trial call through.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Timeouts: map[Operation]time.Duration{
+			OpInterview: 45 * time.Second,
+			OpResume:    30 * time.Second,
+			OpReport:    90 * time.Second,
+		},
+		DefaultTimeout:   45 * time.Second,
+		MaxRetries:       3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         8 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// Timeout returns the deadline of op, retries included. Zero means none.
+func (p Policy) Timeout(op Operation) time.Duration {
+	if t, ok := p.Timeouts[op]; ok {
+		return t
+	}
+	return p.DefaultTimeout
+}
+
+// PolicyFromEnv returns DefaultPolicy with any LLM_* overrides applied.
+func PolicyFromEnv() Policy {
+	p := DefaultPolicy()
+
+	durationEnv := func(key string, target *time.Duration) {
+		if v := os.Getenv(key); v != "" {
+			if d, err := time.ParseDuration(v); err == nil {
+				*target = d
+			} else {
+				log.Printf("Warning: invalid %s %q: %v", key, v, err)
+			}
+		}
+	}
+	intEnv := func(key string, target *int) {
+		if v := os.Getenv(key); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				*target = n
+			} else {
+				log.Printf("Warning: invalid %s %q: %v", key, v, err)
+			}
+		}
+	}
+
+	interview, resume, report := p.Timeouts[OpInterview], p.Timeouts[OpResume], p.Timeouts[OpReport]
+	durationEnv("LLM_TIMEOUT_INTERVIEW", &interview)
+	durationEnv("LLM_TIMEOUT_RESUME", &resume)
+	durationEnv("LLM_TIMEOUT_REPORT", &report)
+	p.Timeouts[OpInterview], p.Timeouts[OpResume], p.Timeouts[OpReport] = interview, resume, report
+
+	durationEnv("LLM_TIMEOUT_DEFAULT", &p.DefaultTimeout)
+	intEnv("LLM_MAX_RETRIES", &p.MaxRetries)
+	durationEnv("LLM_RETRY_BASE_DELAY", &p.BaseDelay)
+	durationEnv("LLM_RETRY_MAX_DELAY", &p.MaxDelay)
+	intEnv("LLM_BREAKER_THRESHOLD", &p.BreakerThreshold)
+	durationEnv("LLM_BREAKER_COOLDOWN", &p.BreakerCooldown)
+
+	return p
+}
+
+// ErrCircuitOpen is matched by errors.Is for calls rejected by the breaker.
+var ErrCircuitOpen = errors.New("LLM provider is temporarily unavailable")
+
+// CircuitOpenError is returned while the breaker is open.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", ErrCircuitOpen, e.RetryAfter.Round(time.Second))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// ResilientProvider wraps another provider with per-operation deadlines,
+// retries with exponential backoff and jitter, and a circuit breaker.
+type ResilientProvider struct {
+	next    LLMProvider
+	policy  Policy
+	breaker *circuitBreaker
+}
+
+func NewResilientProvider(next LLMProvider, policy Policy) *ResilientProvider {
+	return &ResilientProvider{
+		next:    next,
+		policy:  policy,
+		breaker: &circuitBreaker{threshold: policy.BreakerThreshold, cooldown: policy.BreakerCooldown},
+	}
+}
+
+func (p *ResilientProvider) Name() string {
+	return p.next.Name()
+}
+
+func (p *ResilientProvider) Usage() Usage {
+	return p.next.Usage()
+}
+
+func (p *ResilientProvider) GenerateText(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	return p.call(ctx, func(ctx context.Context) (*Response, bool, error) {
+		resp, err := p.next.GenerateText(ctx, prompt, opts...)
+		return resp, true, err
+	})
+}
+
+func (p *ResilientProvider) GenerateWithBlobs(ctx context.Context, prompt string, blobs []Blob, opts ...Option) (*Response, error) {
+	return p.call(ctx, func(ctx context.Context) (*Response, bool, error) {
+		resp, err := p.next.GenerateWithBlobs(ctx, prompt, blobs, opts...)
+		return resp, true, err
+	})
+}
+
+// GenerateTextStream only retries while nothing has been passed to onChunk,
+// so the caller never sees duplicated output.
+func (p *ResilientProvider) GenerateTextStream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) (*Response, error) {
+	return p.call(ctx, func(ctx context.Context) (*Response, bool, error) {
+		streamed := false
+		resp, err := p.next.GenerateTextStream(ctx, prompt, func(chunk string) error {
+			streamed = true
+			return onChunk(chunk)
+		}, opts...)
+		return resp, !streamed, err
+	})
+}
+
+// call runs attempt under the operation deadline, retrying retryable errors.
+// attempt reports whether it is safe to retry after a failure.
+func (p *ResilientProvider) call(ctx context.Context, attempt func(ctx context.Context) (*Response, bool, error)) (*Response, error) {
+	if timeout := p.policy.Timeout(operationFrom(ctx)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for retry := 0; ; retry++ {
+		trial, err := p.breaker.allow()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, canRetry, err := attempt(ctx)
+		if err == nil {
+			p.breaker.success()
+			return resp, nil
+		}
+
+		// Only provider-side failures count towards the breaker; a caller going
+		// away or a bad request says nothing about the provider's health.
+		if IsRetryable(err) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			p.breaker.failure()
+		} else if trial {
+			// Neither outcome, so let the next call probe the provider instead
+			p.breaker.endTrial()
+		}
+
+		if !canRetry || !IsRetryable(err) || retry >= p.policy.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := p.backoff(retry)
+		log.Printf("LLM call failed (%v), retrying in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns a full-jitter exponential delay for the given retry.
+func (p *ResilientProvider) backoff(retry int) time.Duration {
+	ceiling := p.policy.BaseDelay << retry
+	if ceiling <= 0 || ceiling > p.policy.MaxDelay {
+		ceiling = p.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling)))
+}
+
+// IsRetryable reports whether err is a transient provider failure such as a
+// rate limit or an unavailable backend.
+func IsRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return retryableHTTPStatus(statusErr.StatusCode)
+	}
+
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		if s := apiErr.GRPCStatus(); s != nil {
+			switch s.Code() {
+			case codes.ResourceExhausted, codes.Unavailable, codes.Internal, codes.Aborted:
+				return true
+			}
+		}
+		return retryableHTTPStatus(apiErr.HTTPCode())
+	}
+
+	return false
+}
+
+func retryableHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// circuitBreaker is a consecutive-failure breaker with a single trial call
+// in the half-open state.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// allow reports whether a call may go ahead, and whether it is the half-open
+// trial call. A trial must end with success, failure or endTrial.
+func (b *circuitBreaker) allow() (bool, error) {
+	if b.threshold <= 0 {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return false, nil
+	}
+
+	remaining := b.cooldown - time.Since(b.openedAt)
+	if remaining > 0 || b.trial {
+		if remaining <= 0 {
+			remaining = time.Second
+		}
+		return false, &CircuitOpenError{RetryAfter: remaining}
+	}
+
+	// Half-open: let one call through to probe the provider.
+	b.trial = true
+	return true, nil
+}
+
+// endTrial releases the half-open trial without changing the breaker's
+// state, for trial calls that failed for reasons other than the provider.
+func (b *circuitBreaker) endTrial() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A failed half-open trial keeps failures above the threshold, so this
+	// also re-opens the breaker for another cooldown.
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.trial = false
+	}
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// stubProvider returns the queued errors in order, then succeeds.
+type stubProvider struct {
+	errs  []error
+	calls int
+}
+
+func (s *stubProvider) Name() string { return "stub" }
+
+func (s *stubProvider) Usage() Usage { return Usage{} }
+
+func (s *stubProvider) GenerateText(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return &Response{Text: "ok"}, nil
+}
+
+func (s *stubProvider) GenerateWithBlobs(ctx context.Context, prompt string, blobs []Blob, opts ...Option) (*Response, error) {
+	return s.GenerateText(ctx, prompt, opts...)
+}
+
+func (s *stubProvider) GenerateTextStream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) (*Response, error) {
+	return s.GenerateText(ctx, prompt, opts...)
+}
+
+func TestBreakerReleasesTrialOnNonProviderFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		trialErr error
+	}{
+		{"bad request", &StatusError{StatusCode: http.StatusBadRequest}},
+		{"caller canceled", context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unavailable := &StatusError{StatusCode: http.StatusServiceUnavailable}
+			stub := &stubProvider{errs: []error{unavailable, tt.trialErr}}
+			provider := NewResilientProvider(stub, Policy{
+				DefaultTimeout:   time.Second,
+				BreakerThreshold: 1,
+				BreakerCooldown:  10 * time.Millisecond,
+			})
+			ctx := context.Background()
+
+			// Open the breaker
+			if _, err := provider.GenerateText(ctx, "p"); !errors.As(err, new(*StatusError)) {
+				t.Fatalf("first call: got %v, want the provider error", err)
+			}
+			if _, err := provider.GenerateText(ctx, "p"); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("call while open: got %v, want ErrCircuitOpen", err)
+			}
+
+			// Half-open trial fails for a reason unrelated to the provider
+			time.Sleep(20 * time.Millisecond)
+			if _, err := provider.GenerateText(ctx, "p"); !errors.Is(err, tt.trialErr) {
+				t.Fatalf("trial call: got %v, want %v", err, tt.trialErr)
+			}
+
+			// The next call must be let through as a new trial
+			resp, err := provider.GenerateText(ctx, "p")
+			if err != nil {
+				t.Fatalf("call after trial: got %v, want success", err)
+			}
+			if resp.Text != "ok" || stub.calls != 3 {
+				t.Fatalf("call after trial: got %q after %d calls, want \"ok\" after 3", resp.Text, stub.calls)
+			}
+
+			// The successful trial closed the breaker
+			if _, err := provider.GenerateText(ctx, "p"); err != nil {
+				t.Fatalf("call after close: got %v", err)
+			}
+		})
+	}
+}
+
+func TestBreakerReopensOnFailedTrial(t *testing.T) {
+	unavailable := &StatusError{StatusCode: http.StatusServiceUnavailable}
+	stub := &stubProvider{errs: []error{unavailable, unavailable}}
+	provider := NewResilientProvider(stub, Policy{
+		DefaultTimeout:   time.Second,
+		BreakerThreshold: 1,
+		BreakerCooldown:  10 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	provider.GenerateText(ctx, "p")
+	time.Sleep(20 * time.Millisecond)
+	if _, err := provider.GenerateText(ctx, "p"); !errors.Is(err, unavailable) {
+		t.Fatalf("trial call: got %v, want the provider error", err)
+	}
+	if _, err := provider.GenerateText(ctx, "p"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call after failed trial: got %v, want ErrCircuitOpen", err)
+	}
+}
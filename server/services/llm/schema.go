@@ -0,0 +1,58 @@
+package llm
+
+// Schema describes the JSON shape a response must follow. It marshals to a
+// plain JSON Schema document and is converted to each backend's own format.
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+}
+
+// JSON Schema type names understood by every backend.
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// GenerateOptions are the optional settings for a generation call.
+type GenerateOptions struct {
+	// Schema asks the backend for a JSON response matching the schema.
+	Schema *Schema
+	// Model overrides the backend's configured model for this call.
+	Model string
+}
+
+// Option configures a single generation call.
+type Option func(*GenerateOptions)
+
+// WithSchema requests a JSON response that follows schema.
+func WithSchema(schema *Schema) Option {
+	return func(o *GenerateOptions) {
+		o.Schema = schema
+	}
+}
+
+// WithModel sends the call to model instead of the configured one.
+func WithModel(model string) Option {
+	return func(o *GenerateOptions) {
+		o.Model = model
+	}
+}
+
+func applyOptions(opts []Option) GenerateOptions {
+	var o GenerateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ScriptedProvider is a deterministic fake used for local runs and tests.
+//
+// Text calls return TextScript in order and blob calls return BlobScript in
+// order. Each session tagged with WithSession plays its own copy of the
+// scripts, and so does each operation within a session, so concurrent
+// interviews and welcome back recaps do not shift each other's replies.
+// Text calls made for a report return ReportText instead. Once a script is
+// exhausted its last entry is repeated. Only the latest maxScriptPlaybacks
+// playbacks are remembered; an older session starts its scripts over.
+type ScriptedProvider struct {
+	TextScript []string
+	BlobScript []string
+	ReportText string
+
+	mu sync.Mutex
+	// calls counts the calls made so far per script, session and operation,
+	// and playbacks lists its keys oldest first.
+	calls     map[scriptKey]int
+	playbacks []scriptKey
+	usage     Usage
+}
+
+// maxScriptPlaybacks bounds the playbacks a long-running server keeps count
+// of.
+const maxScriptPlaybacks = 1024
+
+// scriptKey identifies one playback of a script.
+type scriptKey struct {
+	blob    bool
+	session string
+	op      Operation
+}
+
+var defaultTextScript = []string{
+	`{
+  "question": "Hello and welcome! Let's get started. Can you explain the difference between a process and a thread?",
+  "code": ""
+}`,
+	`{
+  "rating": 7,
+  "feedback": {
+    "positive": "Clear explanation of the core difference.",
+    "negative": "Did not mention shared memory and context switching costs.",
+    "improvements": "Talk about scheduling and synchronisation primitives."
+  },
+  "question": "What is the time complexity of the following function?",
+  "code": "func sum(n int) int {\n\ttotal := 0\n\tfor i := 0; i < n; i++ {\n\t\ttotal += i\n\t}\n\treturn total\n}"
+}`,
+	`{
+  "rating": 8,
+  "feedback": {
+    "positive": "Correct complexity analysis.",
+    "negative": "Missed the closed form alternative.",
+    "improvements": "Mention that n*(n-1)/2 computes the same value in O(1)."
+  },
+  "question": "How would you design a rate limiter for a public API?",
+  "code": ""
+}`,
+}
+
+var defaultReportText = `{
+  "turnTopics": [
+    {"turn": 1, "topic": "Operating Systems"},
+    {"turn": 2, "topic": "Algorithms"}
+  ],
+  "strengths": ["Explains core concepts clearly", "Analyses complexity correctly"],
+  "weaknesses": ["Skips practical details such as synchronisation costs"],
+  "roadmap": ["Review concurrency primitives", "Practice spotting closed form solutions"],
+  "recommendation": "lean-hire",
+  "summary": "The candidate showed solid fundamentals but left out some depth."
+}`
+
+var defaultBlobScript = []string{
+	`{
+  "name": "Jane Doe",
+  "techStacks": ["Go", "MongoDB", "React"],
+  "experience": "0-2 Years",
+  "projects": [
+    {
+      "title": "Interview Scheduler",
+      "techStacks": ["Go", "MongoDB"],
+      "description": "A service for booking and tracking mock interviews."
+    }
+  ]
+}`,
+}
+
+// NewScriptedProvider returns a fake that plays a short canned interview and
+// resume extraction.
+func NewScriptedProvider() *ScriptedProvider {
+	return &ScriptedProvider{
+		TextScript: defaultTextScript,
+		BlobScript: defaultBlobScript,
+		ReportText: defaultReportText,
+	}
+}
+
+func (s *ScriptedProvider) Name() string {
+	return "fake/scripted"
+}
+
+func (s *ScriptedProvider) GenerateText(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if operationFrom(ctx) == OpReport && s.ReportText != "" {
+		return s.respond(prompt, s.ReportText), nil
+	}
+
+	text := s.next(ctx, s.TextScript, false)
+	return s.respond(prompt, text), nil
+}
+
+func (s *ScriptedProvider) GenerateWithBlobs(ctx context.Context, prompt string, blobs []Blob, opts ...Option) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	text := s.next(ctx, s.BlobScript, true)
+	return s.respond(prompt, text), nil
+}
+
+// GenerateTextStream replays the next text script entry line by line.
+func (s *ScriptedProvider) GenerateTextStream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) (*Response, error) {
+	resp, err := s.GenerateText(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.SplitAfter(resp.Text, "\n") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onChunk(line); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (s *ScriptedProvider) Usage() Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage
+}
+
+// respond records an approximate usage (one token per word) and must be
+// called with s.mu held.
+func (s *ScriptedProvider) respond(prompt string, text string) *Response {
+	usage := Usage{
+		PromptTokens:    len(strings.Fields(prompt)),
+		CandidateTokens: len(strings.Fields(text)),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CandidateTokens
+	s.usage.Add(usage)
+
+	return &Response{Text: text, Model: "scripted", Usage: usage}
+}
+
+// next returns the next entry of script for the session and operation of
+// ctx, and must be called with s.mu held.
+func (s *ScriptedProvider) next(ctx context.Context, script []string, blob bool) string {
+	key := scriptKey{blob: blob, session: sessionFrom(ctx), op: operationFrom(ctx)}
+	if s.calls == nil {
+		s.calls = map[scriptKey]int{}
+	}
+	call, ok := s.calls[key]
+	if !ok {
+		if len(s.playbacks) == maxScriptPlaybacks {
+			delete(s.calls, s.playbacks[0])
+			s.playbacks = s.playbacks[1:]
+		}
+		s.playbacks = append(s.playbacks, key)
+	}
+	s.calls[key] = call + 1
+
+	if len(script) == 0 {
+		return ""
+	}
+	if call >= len(script) {
+		return script[len(script)-1]
+	}
+	return script[call]
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestScriptedProviderPlaysEachSessionSeparately(t *testing.T) {
+	provider := &ScriptedProvider{TextScript: []string{"first", "second", "third"}}
+	interview := func(session string) context.Context {
+		return WithSession(WithOperation(context.Background(), OpInterview), session)
+	}
+
+	calls := []struct {
+		ctx  context.Context
+		want string
+	}{
+		{interview("a"), "first"},
+		{interview("b"), "first"},
+		{interview("a"), "second"},
+		// A recap plays its own copy and does not shift the interview
+		{WithSession(WithOperation(context.Background(), OpRecap), "a"), "first"},
+		{interview("a"), "third"},
+		{interview("b"), "second"},
+		// The last entry repeats once the script is exhausted
+		{interview("a"), "third"},
+	}
+
+	for i, call := range calls {
+		resp, err := provider.GenerateText(call.ctx, "prompt")
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if resp.Text != call.want {
+			t.Errorf("call %d: got %q, want %q", i, resp.Text, call.want)
+		}
+	}
+}
+
+func TestScriptedProviderForgetsOldPlaybacks(t *testing.T) {
+	provider := &ScriptedProvider{TextScript: []string{"first", "second"}}
+	interview := func(session int) context.Context {
+		return WithSession(WithOperation(context.Background(), OpInterview), strconv.Itoa(session))
+	}
+
+	for session := 0; session <= maxScriptPlaybacks; session++ {
+		if _, err := provider.GenerateText(interview(session), "prompt"); err != nil {
+			t.Fatalf("session %d: %v", session, err)
+		}
+	}
+	if len(provider.calls) != maxScriptPlaybacks || len(provider.playbacks) != maxScriptPlaybacks {
+		t.Fatalf("tracking %d counters and %d playbacks, want %d", len(provider.calls), len(provider.playbacks), maxScriptPlaybacks)
+	}
+
+	// The newest sessions carry on, the oldest one starts over
+	for _, call := range []struct {
+		session int
+		want    string
+	}{
+		{maxScriptPlaybacks, "second"},
+		{1, "second"},
+		{0, "first"},
+	} {
+		resp, err := provider.GenerateText(interview(call.session), "prompt")
+		if err != nil {
+			t.Fatalf("session %d: %v", call.session, err)
+		}
+		if resp.Text != call.want {
+			t.Errorf("session %d: got %q, want %q", call.session, resp.Text, call.want)
+		}
+	}
+}
@@ -0,0 +1,908 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMemoryStores returns thread-safe in-memory stores for running the API
+// without MongoDB. Nothing survives a restart.
+func NewMemoryStores() *Stores {
+	sessions := NewMemorySessionStore()
+	turns := NewMemoryTurnStore()
+	return &Stores{
+		Backend:  "memory",
+		Sessions: sessions,
+		Turns:    turns,
+		Users:    NewMemoryUserStore(),
+		Orgs:     NewMemoryOrgStore(),
+		Audit:    NewMemoryAuditStore(),
+		Notes:    NewMemoryNoteStore(),
+		Progress: NewMemoryProgressStore(sessions, turns),
+		Usage:    NewMemoryUsageStore(),
+	}
+}
+
+// clone deep-copies v through BSON, so stored documents never share memory
+// with callers and round-trip exactly as they would through MongoDB.
+func clone[T any](v *T) (*T, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out T
+	if err := bson.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// applySet applies a $set-style update with dotted paths to v.
+func applySet[T any](v *T, fields bson.M) (*T, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	for path, value := range fields {
+		keys := strings.Split(path, ".")
+		parent := doc
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := parent[key].(bson.M)
+			if !ok {
+				child = bson.M{}
+				parent[key] = child
+			}
+			parent = child
+		}
+		parent[keys[len(keys)-1]] = value
+	}
+
+	if data, err = bson.Marshal(doc); err != nil {
+		return nil, err
+	}
+	var out T
+	if err := bson.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MemorySessionStore keeps sessions in a map.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[primitive.ObjectID]*models.Session
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: map[primitive.ObjectID]*models.Session{}}
+}
+
+func (s *MemorySessionStore) CreateSession(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	stored, err := clone(session)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.sessions[session.ID]; exists {
+		return fmt.Errorf("failed to insert session: duplicate ID %s", session.ID.Hex())
+	}
+	s.sessions[session.ID] = stored
+	return nil
+}
+
+func (s *MemorySessionStore) GetSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID) (*models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	return clone(session)
+}
+
+func (s *MemorySessionStore) ListSessions(ctx context.Context, filter SessionFilter) ([]models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := []models.Session{}
+	for _, session := range s.sessions {
+		if !filter.matches(session) {
+			continue
+		}
+		copied, err := clone(session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode sessions: %v", err)
+		}
+		sessions = append(sessions, *copied)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return newerSession(&sessions[i], sessions[j].CreatedAt, sessions[j].ID)
+	})
+	if filter.Limit > 0 && len(sessions) > filter.Limit {
+		sessions = sessions[:filter.Limit]
+	}
+	return sessions, nil
+}
+
+// newerSession reports whether session sorts before the position given by
+// createdAt and id in a newest-first listing.
+func newerSession(session *models.Session, createdAt time.Time, id primitive.ObjectID) bool {
+	if !session.CreatedAt.Equal(createdAt) {
+		return session.CreatedAt.After(createdAt)
+	}
+	return bytes.Compare(session.ID[:], id[:]) > 0
+}
+
+func (f *SessionFilter) matches(session *models.Session) bool {
+	if session.OrgID != f.OrgID {
+		return false
+	}
+	if !f.UserID.IsZero() && session.UserID != f.UserID {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, session.InterviewStatus) {
+		return false
+	}
+	if f.TechStack != "" && !slices.ContainsFunc(session.TechStacks, func(stack string) bool {
+		return strings.EqualFold(stack, f.TechStack)
+	}) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && session.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !session.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	// Only sessions strictly after the cursor belong to the next page
+	if f.After != nil && (session.ID == f.After.ID || newerSession(session, f.After.CreatedAt, f.After.ID)) {
+		return false
+	}
+	return true
+}
+
+func (s *MemorySessionStore) UpdateSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, fields bson.M) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	updated, err := applySet(session, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %v", err)
+	}
+	s.sessions[id] = updated
+	return clone(updated)
+}
+
+func (s *MemorySessionStore) TransitionSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, from models.AllowedInterviewStatus, to models.AllowedInterviewStatus, fields bson.M) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	if session.InterviewStatus != from {
+		return nil, ErrConflict
+	}
+
+	set := bson.M{"interviewstatus": to}
+	for key, value := range fields {
+		set[key] = value
+	}
+	updated, err := applySet(session, set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session status: %v", err)
+	}
+	s.sessions[id] = updated
+	return clone(updated)
+}
+
+func (s *MemorySessionStore) ExpireSessions(ctx context.Context, from []models.AllowedInterviewStatus, cutoffs ExpiryCutoffs, fields bson.M) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set := bson.M{"interviewstatus": models.Expired}
+	for key, value := range fields {
+		set[key] = value
+	}
+
+	var expired int64
+	for id, session := range s.sessions {
+		if !slices.Contains(from, session.InterviewStatus) || !cutoffs.IsStale(session) {
+			continue
+		}
+
+		updated, err := applySet(session, set)
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire sessions: %v", err)
+		}
+		s.sessions[id] = updated
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *MemorySessionStore) SaveReport(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, report *models.Report) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	if session.Report == nil {
+		updated, err := applySet(session, bson.M{"report": report})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save report: %v", err)
+		}
+		s.sessions[id] = updated
+		session = updated
+	}
+	return clone(session)
+}
+
+func (s *MemorySessionStore) ClaimSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, guestTokenHash string, userId primitive.ObjectID, claimedAt time.Time) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	if session.UserType != models.Guest || session.GuestTokenHash != guestTokenHash {
+		return nil, ErrConflict
+	}
+
+	updated, err := applySet(session, bson.M{
+		"userType":       models.User,
+		"userID":         userId,
+		"guestTokenHash": "",
+		"claimedAt":      claimedAt,
+		"updatedAt":      claimedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim session: %v", err)
+	}
+	s.sessions[id] = updated
+	return clone(updated)
+}
+
+func (s *MemorySessionStore) AddUsage(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, usage models.UsageTotals) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.OrgID != orgId {
+		return ErrNotFound
+	}
+	session.Usage.PromptTokens += usage.PromptTokens
+	session.Usage.CandidateTokens += usage.CandidateTokens
+	session.Usage.TotalTokens += usage.TotalTokens
+	session.Usage.EstimatedCost += usage.EstimatedCost
+	session.Usage.Calls += usage.Calls
+	return nil
+}
+
+// MemoryTurnStore keeps question documents in a map keyed by session.
+type MemoryTurnStore struct {
+	mu        sync.RWMutex
+	questions map[primitive.ObjectID]*models.Question
+}
+
+func NewMemoryTurnStore() *MemoryTurnStore {
+	return &MemoryTurnStore{questions: map[primitive.ObjectID]*models.Question{}}
+}
+
+func (s *MemoryTurnStore) CreateQuestion(ctx context.Context, question *models.Question) error {
+	if question.ID.IsZero() {
+		question.ID = primitive.NewObjectID()
+	}
+	stored, err := clone(question)
+	if err != nil {
+		return fmt.Errorf("failed to insert question document: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.questions[question.SessionId]; exists {
+		return ErrConflict
+	}
+	s.questions[question.SessionId] = stored
+	return nil
+}
+
+func (s *MemoryTurnStore) GetQuestion(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) (*models.Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	question, ok := s.questions[sessionId]
+	if !ok || question.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	return clone(question)
+}
+
+func (s *MemoryTurnStore) AppendTurn(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, answered models.Turn, next models.Turn) (*models.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, ok := s.questions[sessionId]
+	if !ok || question.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	if answered.Number < 1 || len(question.Turns) != answered.Number {
+		return nil, ErrConflict
+	}
+
+	updated, err := clone(question)
+	if err != nil {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+	updated.Turns[answered.Number-1] = answered
+	updated.Turns = append(updated.Turns, next)
+	updated.UpdatedAt = time.Now()
+
+	if updated, err = clone(updated); err != nil {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+	s.questions[sessionId] = updated
+	return clone(updated)
+}
+
+func (s *MemoryTurnStore) ReviewTurn(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, number int, review models.TurnReview) (*models.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, ok := s.questions[sessionId]
+	if !ok || question.OrgID != orgId || number < 1 || number > len(question.Turns) {
+		return nil, ErrNotFound
+	}
+	if question.Turns[number-1].Rating == nil {
+		return nil, ErrConflict
+	}
+
+	updated, err := clone(question)
+	if err != nil {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+	updated.Turns[number-1].Review = &review
+	updated.UpdatedAt = time.Now()
+
+	if updated, err = clone(updated); err != nil {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+	s.questions[sessionId] = updated
+	return clone(updated)
+}
+
+func (s *MemoryTurnStore) ListReviewedTurns(ctx context.Context, orgId primitive.ObjectID) ([]models.Turn, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	turns := []models.Turn{}
+	for _, question := range s.questions {
+		if question.OrgID != orgId {
+			continue
+		}
+		for i := range question.Turns {
+			if question.Turns[i].Review == nil {
+				continue
+			}
+			copied, err := clone(&question.Turns[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode reviewed turns: %v", err)
+			}
+			turns = append(turns, *copied)
+		}
+	}
+	return turns, nil
+}
+
+// MemoryUserStore keeps user accounts in a map.
+type MemoryUserStore struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]*models.UserAccount
+}
+
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{users: map[primitive.ObjectID]*models.UserAccount{}}
+}
+
+func (s *MemoryUserStore) CreateUser(ctx context.Context, user *models.UserAccount) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	stored, err := clone(user)
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.users {
+		if existing.OrgID == user.OrgID && existing.Email == user.Email {
+			return ErrConflict
+		}
+	}
+	s.users[user.ID] = stored
+	return nil
+}
+
+func (s *MemoryUserStore) GetUser(ctx context.Context, id primitive.ObjectID) (*models.UserAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(user)
+}
+
+func (s *MemoryUserStore) GetUserByEmail(ctx context.Context, orgId primitive.ObjectID, email string) (*models.UserAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.OrgID == orgId && user.Email == email {
+			return clone(user)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryUserStore) ListUsers(ctx context.Context, orgId primitive.ObjectID) ([]models.UserAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := []models.UserAccount{}
+	for _, user := range s.users {
+		if user.OrgID != orgId {
+			continue
+		}
+		copied, err := clone(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode users: %v", err)
+		}
+		users = append(users, *copied)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	return users, nil
+}
+
+func (s *MemoryUserStore) SetUserRole(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, role models.Role) (*models.UserAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.users[id]
+	if !ok || stored.OrgID != orgId {
+		return nil, ErrNotFound
+	}
+	stored.Role = role
+	stored.UpdatedAt = time.Now()
+	return clone(stored)
+}
+
+// MemoryOrgStore keeps organizations in a map.
+type MemoryOrgStore struct {
+	mu   sync.RWMutex
+	orgs map[primitive.ObjectID]*models.Organization
+}
+
+func NewMemoryOrgStore() *MemoryOrgStore {
+	return &MemoryOrgStore{orgs: map[primitive.ObjectID]*models.Organization{}}
+}
+
+func (s *MemoryOrgStore) CreateOrg(ctx context.Context, org *models.Organization) error {
+	if org.ID.IsZero() {
+		org.ID = primitive.NewObjectID()
+	}
+	stored, err := clone(org)
+	if err != nil {
+		return fmt.Errorf("failed to insert organization: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[org.ID] = stored
+	return nil
+}
+
+func (s *MemoryOrgStore) GetOrg(ctx context.Context, id primitive.ObjectID) (*models.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(org)
+}
+
+func (s *MemoryOrgStore) GetOrgByAPIKey(ctx context.Context, keyHash string) (*models.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, org := range s.orgs {
+		for _, key := range org.APIKeys {
+			if key.Hash == keyHash && key.RevokedAt == nil {
+				return clone(org)
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryOrgStore) ListOrgs(ctx context.Context) ([]models.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orgs := make([]models.Organization, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		copied, err := clone(org)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode organizations: %v", err)
+		}
+		orgs = append(orgs, *copied)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].CreatedAt.Before(orgs[j].CreatedAt) })
+	return orgs, nil
+}
+
+func (s *MemoryOrgStore) UpdateOrgConfig(ctx context.Context, id primitive.ObjectID, config models.OrgConfig) (*models.Organization, error) {
+	return s.updateOrg(id, func(org *models.Organization) bool {
+		org.Config = config
+		return true
+	})
+}
+
+func (s *MemoryOrgStore) AddAPIKey(ctx context.Context, id primitive.ObjectID, key models.APIKey) (*models.Organization, error) {
+	return s.updateOrg(id, func(org *models.Organization) bool {
+		org.APIKeys = append(org.APIKeys, key)
+		return true
+	})
+}
+
+func (s *MemoryOrgStore) RevokeAPIKey(ctx context.Context, id primitive.ObjectID, keyId string, revokedAt time.Time) (*models.Organization, error) {
+	return s.updateOrg(id, func(org *models.Organization) bool {
+		for i := range org.APIKeys {
+			if org.APIKeys[i].ID == keyId && org.APIKeys[i].RevokedAt == nil {
+				org.APIKeys[i].RevokedAt = &revokedAt
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// updateOrg applies update to a copy of the organization and stores it if
+// update reports a change.
+func (s *MemoryOrgStore) updateOrg(id primitive.ObjectID, update func(org *models.Organization) bool) (*models.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	org, err := clone(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update organization: %v", err)
+	}
+	if !update(org) {
+		return nil, ErrNotFound
+	}
+	org.UpdatedAt = time.Now()
+
+	updated, err := clone(org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update organization: %v", err)
+	}
+	s.orgs[id] = updated
+	return org, nil
+}
+
+// MemoryAuditStore keeps audit events in a slice.
+type MemoryAuditStore struct {
+	mu     sync.RWMutex
+	events []models.AuditEvent
+}
+
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{}
+}
+
+func (s *MemoryAuditStore) RecordEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	stored, err := clone(event)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, *stored)
+	return nil
+}
+
+func (s *MemoryAuditStore) ListEvents(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Events are appended in order, so they are already oldest first.
+	events := []models.AuditEvent{}
+	for _, event := range s.events {
+		if event.OrgID == orgId && event.SessionID == sessionId {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// MemoryNoteStore keeps reviewer notes in a slice.
+type MemoryNoteStore struct {
+	mu    sync.RWMutex
+	notes []models.ReviewNote
+}
+
+func NewMemoryNoteStore() *MemoryNoteStore {
+	return &MemoryNoteStore{}
+}
+
+func (s *MemoryNoteStore) AddNote(ctx context.Context, note *models.ReviewNote) error {
+	if note.ID.IsZero() {
+		note.ID = primitive.NewObjectID()
+	}
+	stored, err := clone(note)
+	if err != nil {
+		return fmt.Errorf("failed to insert note: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notes = append(s.notes, *stored)
+	return nil
+}
+
+func (s *MemoryNoteStore) ListNotes(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.ReviewNote, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Notes are appended in order, so they are already oldest first.
+	notes := []models.ReviewNote{}
+	for _, note := range s.notes {
+		if note.OrgID == orgId && note.SessionID == sessionId {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+// MemoryUsageStore keeps usage records in a slice.
+type MemoryUsageStore struct {
+	mu      sync.RWMutex
+	records []models.UsageRecord
+}
+
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{}
+}
+
+func (s *MemoryUsageStore) InsertUsage(ctx context.Context, record models.UsageRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *MemoryUsageStore) ListUsage(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.UsageRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Records are appended in call order, so they are already oldest first.
+	records := []models.UsageRecord{}
+	for _, record := range s.records {
+		if record.OrgID == orgId && record.SessionId == sessionId {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (s *MemoryUsageStore) DailyUsage(ctx context.Context, filter UsageFilter) ([]models.DailyUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byDay := map[string]*models.DailyUsage{}
+	for _, record := range s.records {
+		if !filter.AllOrgs && record.OrgID != filter.OrgID {
+			continue
+		}
+		if (filter.From != "" && record.Day < filter.From) || (filter.To != "" && record.Day > filter.To) {
+			continue
+		}
+		day, ok := byDay[record.Day]
+		if !ok {
+			day = &models.DailyUsage{Day: record.Day}
+			byDay[record.Day] = day
+		}
+		day.PromptTokens += record.PromptTokens
+		day.CandidateTokens += record.CandidateTokens
+		day.TotalTokens += record.TotalTokens
+		day.EstimatedCost += record.EstimatedCost
+		day.Calls++
+	}
+
+	days := make([]models.DailyUsage, 0, len(byDay))
+	for _, day := range byDay {
+		days = append(days, *day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Day < days[j].Day })
+	return days, nil
+}
+
+// MemoryProgressStore aggregates progress from the in-memory session and
+// turn stores, matching what the Mongo pipelines compute.
+type MemoryProgressStore struct {
+	sessions *MemorySessionStore
+	turns    *MemoryTurnStore
+}
+
+func NewMemoryProgressStore(sessions *MemorySessionStore, turns *MemoryTurnStore) *MemoryProgressStore {
+	return &MemoryProgressStore{sessions: sessions, turns: turns}
+}
+
+// progressSessions returns copies of the user's sessions in the filter's
+// window, oldest first.
+func (s *MemoryProgressStore) progressSessions(filter ProgressFilter) ([]models.Session, error) {
+	s.sessions.mu.RLock()
+	defer s.sessions.mu.RUnlock()
+
+	var sessions []models.Session
+	for _, session := range s.sessions.sessions {
+		if session.OrgID != filter.OrgID || session.UserID != filter.UserID {
+			continue
+		}
+		if !filter.From.IsZero() && session.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !session.CreatedAt.Before(filter.To) {
+			continue
+		}
+		copied, err := clone(session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate progress: %v", err)
+		}
+		sessions = append(sessions, *copied)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	return sessions, nil
+}
+
+// progressBuckets accumulates the points of one aggregation.
+type progressBuckets struct {
+	order  []string
+	points map[string]*models.ProgressPoint
+	sums   map[string]float64
+}
+
+func newProgressBuckets() *progressBuckets {
+	return &progressBuckets{points: map[string]*models.ProgressPoint{}, sums: map[string]float64{}}
+}
+
+// add counts one session towards area in period with questions ratings
+// summing to sum. Areas are matched without regard to case.
+func (b *progressBuckets) add(area string, period time.Time, sum float64, questions int) {
+	key := strings.ToLower(area) + "\x00" + period.Format(time.RFC3339)
+	point, ok := b.points[key]
+	if !ok {
+		point = &models.ProgressPoint{Area: area, Period: period}
+		b.points[key] = point
+		b.order = append(b.order, key)
+	}
+	point.Sessions++
+	point.Questions += questions
+	b.sums[key] += sum
+}
+
+func (b *progressBuckets) result() []models.ProgressPoint {
+	points := []models.ProgressPoint{}
+	for _, key := range b.order {
+		point := *b.points[key]
+		if point.Questions == 0 {
+			continue
+		}
+		point.Score = b.sums[key] / float64(point.Questions)
+		points = append(points, point)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		if ai, aj := strings.ToLower(points[i].Area), strings.ToLower(points[j].Area); ai != aj {
+			return ai < aj
+		}
+		return points[i].Period.Before(points[j].Period)
+	})
+	return points
+}
+
+func (s *MemoryProgressStore) StackProgress(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error) {
+	sessions, err := s.progressSessions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.turns.mu.RLock()
+	defer s.turns.mu.RUnlock()
+
+	buckets := newProgressBuckets()
+	for _, session := range sessions {
+		question, ok := s.turns.questions[session.ID]
+		if !ok {
+			continue
+		}
+
+		var sum, questions int
+		for i := range question.Turns {
+			if rating := question.Turns[i].EffectiveRating(); rating != nil {
+				sum += *rating
+				questions++
+			}
+		}
+
+		period := filter.Period.Truncate(session.CreatedAt)
+		for _, stack := range session.TechStacks {
+			buckets.add(stack, period, float64(sum), questions)
+		}
+	}
+	return buckets.result(), nil
+}
+
+func (s *MemoryProgressStore) TopicProgress(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error) {
+	sessions, err := s.progressSessions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := newProgressBuckets()
+	for _, session := range sessions {
+		if session.Report == nil {
+			continue
+		}
+		period := filter.Period.Truncate(session.CreatedAt)
+		for _, topic := range session.Report.TopicScores {
+			buckets.add(topic.Topic, period, topic.Score*float64(topic.Questions), topic.Questions)
+		}
+	}
+	return buckets.result(), nil
+}
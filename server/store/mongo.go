@@ -0,0 +1,850 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Indexes backing the queries below. They are created at startup.
+var (
+	sessionIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "orgID", Value: 1}, {Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "orgID", Value: 1}, {Key: "createdAt", Value: -1}}},
+		// The expiry sweeper looks for live sessions by last update
+		{Keys: bson.D{{Key: "interviewstatus", Value: 1}, {Key: "updatedAt", Value: 1}}},
+	}
+	questionIndexes = []mongo.IndexModel{
+		// A session has exactly one question document.
+		{Keys: bson.D{{Key: "sessionid", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+	userIndexes = []mongo.IndexModel{
+		// Emails are unique within a tenant.
+		{Keys: bson.D{{Key: "orgID", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+	orgIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "apiKeys.hash", Value: 1}}},
+	}
+	auditIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "sessionID", Value: 1}, {Key: "createdAt", Value: 1}}},
+	}
+	noteIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "sessionID", Value: 1}, {Key: "createdAt", Value: 1}}},
+	}
+	usageIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "sessionid", Value: 1}, {Key: "createdAt", Value: 1}}},
+		{Keys: bson.D{{Key: "day", Value: 1}}},
+	}
+)
+
+// tenantFilter limits filter to the documents of tenant orgId. Documents
+// outside any organization have no orgID, which matches null.
+func tenantFilter(orgId primitive.ObjectID, filter bson.M) bson.M {
+	if orgId.IsZero() {
+		filter["orgID"] = nil
+	} else {
+		filter["orgID"] = orgId
+	}
+	return filter
+}
+
+// MongoSessionStore stores sessions in a MongoDB collection.
+type MongoSessionStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSessionStore(collection *mongo.Collection) *MongoSessionStore {
+	return &MongoSessionStore{collection: collection}
+}
+
+func (s *MongoSessionStore) CreateSession(ctx context.Context, session *models.Session) error {
+	result, err := s.collection.InsertOne(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %v", err)
+	}
+	session.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoSessionStore) GetSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	err := s.collection.FindOne(ctx, tenantFilter(orgId, bson.M{"_id": id})).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch session: %v", err)
+	}
+	return &session, nil
+}
+
+func (s *MongoSessionStore) ListSessions(ctx context.Context, filter SessionFilter) ([]models.Session, error) {
+	query := tenantFilter(filter.OrgID, bson.M{})
+	if !filter.UserID.IsZero() {
+		query["userID"] = filter.UserID
+	}
+	if len(filter.Statuses) > 0 {
+		query["interviewstatus"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.TechStack != "" {
+		query["techStacks"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.TechStack) + "$", Options: "i"}
+	}
+
+	createdAt := bson.M{}
+	if !filter.CreatedAfter.IsZero() {
+		createdAt["$gte"] = filter.CreatedAfter
+	}
+	if !filter.CreatedBefore.IsZero() {
+		createdAt["$lt"] = filter.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	if filter.After != nil {
+		query["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": filter.After.CreatedAt}},
+			bson.M{"createdAt": filter.After.CreatedAt, "_id": bson.M{"$lt": filter.After.ID}},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %v", err)
+	}
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions: %v", err)
+	}
+	return sessions, nil
+}
+
+func (s *MongoSessionStore) UpdateSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, fields bson.M) (*models.Session, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var session models.Session
+	err := s.collection.FindOneAndUpdate(ctx, tenantFilter(orgId, bson.M{"_id": id}), bson.M{"$set": fields}, opts).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update session: %v", err)
+	}
+	return &session, nil
+}
+
+func (s *MongoSessionStore) TransitionSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, from models.AllowedInterviewStatus, to models.AllowedInterviewStatus, fields bson.M) (*models.Session, error) {
+	set := bson.M{"interviewstatus": to}
+	for key, value := range fields {
+		set[key] = value
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var session models.Session
+	err := s.collection.FindOneAndUpdate(ctx, tenantFilter(orgId, bson.M{"_id": id, "interviewstatus": from}), bson.M{"$set": set}, opts).Decode(&session)
+	if err == nil {
+		return &session, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to update session status: %v", err)
+	}
+
+	// Nothing matched: either the session is gone or its status moved on.
+	count, err := s.collection.CountDocuments(ctx, tenantFilter(orgId, bson.M{"_id": id}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session status: %v", err)
+	}
+	if count == 0 {
+		return nil, ErrNotFound
+	}
+	return nil, ErrConflict
+}
+
+func (s *MongoSessionStore) ExpireSessions(ctx context.Context, from []models.AllowedInterviewStatus, cutoffs ExpiryCutoffs, fields bson.M) (int64, error) {
+	live := bson.M{"$ne": models.Paused}
+	stale := bson.A{}
+	if !cutoffs.InactiveBefore.IsZero() {
+		stale = append(stale, bson.M{
+			"interviewstatus": live,
+			"updatedAt":       bson.M{"$lt": cutoffs.InactiveBefore},
+		})
+	}
+	if !cutoffs.StartedBefore.IsZero() {
+		// createdAt + pausedSeconds < StartedBefore
+		stale = append(stale, bson.M{
+			"interviewstatus": live,
+			"$expr": bson.M{"$lt": bson.A{
+				bson.M{"$add": bson.A{"$createdAt", bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$pausedSeconds", 0}}, 1000}}}},
+				cutoffs.StartedBefore,
+			}},
+		})
+	}
+	if !cutoffs.PausedBefore.IsZero() {
+		stale = append(stale, bson.M{
+			"interviewstatus": models.Paused,
+			"pausedAt":        bson.M{"$lt": cutoffs.PausedBefore},
+		})
+	}
+	if len(stale) == 0 || len(from) == 0 {
+		return 0, nil
+	}
+
+	set := bson.M{"interviewstatus": models.Expired}
+	for key, value := range fields {
+		set[key] = value
+	}
+	filter := bson.M{
+		"interviewstatus": bson.M{"$in": from},
+		"$or":             stale,
+	}
+
+	result, err := s.collection.UpdateMany(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire sessions: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *MongoSessionStore) SaveReport(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, report *models.Report) (*models.Session, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	filter := tenantFilter(orgId, bson.M{"_id": id, "report": bson.M{"$exists": false}})
+
+	var session models.Session
+	err := s.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"report": report}}, opts).Decode(&session)
+	if err == nil {
+		return &session, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to save report: %v", err)
+	}
+
+	// Either the session is gone or another request saved its report first
+	return s.GetSession(ctx, orgId, id)
+}
+
+func (s *MongoSessionStore) ClaimSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, guestTokenHash string, userId primitive.ObjectID, claimedAt time.Time) (*models.Session, error) {
+	filter := tenantFilter(orgId, bson.M{"_id": id, "userType": models.Guest, "guestTokenHash": guestTokenHash})
+	update := bson.M{
+		"$set": bson.M{
+			"userType":  models.User,
+			"userID":    userId,
+			"claimedAt": claimedAt,
+			"updatedAt": claimedAt,
+		},
+		"$unset": bson.M{"guestTokenHash": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var session models.Session
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&session)
+	if err == nil {
+		return &session, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to claim session: %v", err)
+	}
+
+	// Nothing matched: either the session is gone or it was claimed already.
+	count, err := s.collection.CountDocuments(ctx, tenantFilter(orgId, bson.M{"_id": id}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim session: %v", err)
+	}
+	if count == 0 {
+		return nil, ErrNotFound
+	}
+	return nil, ErrConflict
+}
+
+func (s *MongoSessionStore) AddUsage(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, usage models.UsageTotals) error {
+	update := bson.M{
+		"$inc": bson.M{
+			"usage.promptTokens":    usage.PromptTokens,
+			"usage.candidateTokens": usage.CandidateTokens,
+			"usage.totalTokens":     usage.TotalTokens,
+			"usage.estimatedCost":   usage.EstimatedCost,
+			"usage.calls":           usage.Calls,
+		},
+	}
+	result, err := s.collection.UpdateOne(ctx, tenantFilter(orgId, bson.M{"_id": id}), update)
+	if err != nil {
+		return fmt.Errorf("failed to update session usage: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MongoTurnStore stores question documents in a MongoDB collection.
+type MongoTurnStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoTurnStore(collection *mongo.Collection) *MongoTurnStore {
+	return &MongoTurnStore{collection: collection}
+}
+
+func (s *MongoTurnStore) CreateQuestion(ctx context.Context, question *models.Question) error {
+	if question.ID.IsZero() {
+		question.ID = primitive.NewObjectID()
+	}
+	if _, err := s.collection.InsertOne(ctx, question); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to insert question document: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoTurnStore) GetQuestion(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) (*models.Question, error) {
+	var question models.Question
+	err := s.collection.FindOne(ctx, tenantFilter(orgId, bson.M{"sessionid": sessionId})).Decode(&question)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch question: %v", err)
+	}
+	return &question, nil
+}
+
+// AppendTurn writes both turns in a single update. Turn numbers are 1-based,
+// so the answered turn lives at index Number-1, and setting the index just
+// past the end of the array appends the next turn.
+func (s *MongoTurnStore) AppendTurn(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, answered models.Turn, next models.Turn) (*models.Question, error) {
+	answeredPath := fmt.Sprintf("turns.%d", answered.Number-1)
+	nextPath := fmt.Sprintf("turns.%d", answered.Number)
+	update := bson.M{
+		"$set": bson.M{
+			answeredPath: answered,
+			nextPath:     next,
+			"updatedAt":  time.Now(),
+		},
+	}
+
+	// The filter guarantees the answered turn is still the latest one.
+	filter := tenantFilter(orgId, bson.M{
+		"sessionid":  sessionId,
+		answeredPath: bson.M{"$exists": true},
+		nextPath:     bson.M{"$exists": false},
+	})
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var question models.Question
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&question)
+	if err == nil {
+		return &question, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+
+	// Nothing matched: either there is no document or the turn moved on.
+	count, err := s.collection.CountDocuments(ctx, tenantFilter(orgId, bson.M{"sessionid": sessionId}))
+	if err != nil {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+	if count == 0 {
+		return nil, ErrNotFound
+	}
+	return nil, ErrConflict
+}
+
+func (s *MongoTurnStore) ReviewTurn(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, number int, review models.TurnReview) (*models.Question, error) {
+	if number < 1 {
+		return nil, ErrNotFound
+	}
+	turnPath := fmt.Sprintf("turns.%d", number-1)
+	update := bson.M{
+		"$set": bson.M{
+			turnPath + ".review": review,
+			"updatedAt":          time.Now(),
+		},
+	}
+	filter := tenantFilter(orgId, bson.M{
+		"sessionid":          sessionId,
+		turnPath + ".rating": bson.M{"$exists": true},
+	})
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var question models.Question
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&question)
+	if err == nil {
+		return &question, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+
+	// Nothing matched: either there is no such turn or it is not rated.
+	count, err := s.collection.CountDocuments(ctx, tenantFilter(orgId, bson.M{
+		"sessionid": sessionId,
+		turnPath:    bson.M{"$exists": true},
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("database error during update: %v", err)
+	}
+	if count == 0 {
+		return nil, ErrNotFound
+	}
+	return nil, ErrConflict
+}
+
+func (s *MongoTurnStore) ListReviewedTurns(ctx context.Context, orgId primitive.ObjectID) ([]models.Turn, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: tenantFilter(orgId, bson.M{"turns.review": bson.M{"$exists": true}})}},
+		{{Key: "$unwind", Value: "$turns"}},
+		{{Key: "$match", Value: bson.M{"turns.review": bson.M{"$exists": true}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$turns"}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reviewed turns: %v", err)
+	}
+	turns := []models.Turn{}
+	if err := cursor.All(ctx, &turns); err != nil {
+		return nil, fmt.Errorf("failed to decode reviewed turns: %v", err)
+	}
+	return turns, nil
+}
+
+// MongoUserStore stores user accounts in a MongoDB collection.
+type MongoUserStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoUserStore(collection *mongo.Collection) *MongoUserStore {
+	return &MongoUserStore{collection: collection}
+}
+
+func (s *MongoUserStore) CreateUser(ctx context.Context, user *models.UserAccount) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if _, err := s.collection.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to insert user: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoUserStore) GetUser(ctx context.Context, id primitive.ObjectID) (*models.UserAccount, error) {
+	return s.findUser(ctx, bson.M{"_id": id})
+}
+
+func (s *MongoUserStore) GetUserByEmail(ctx context.Context, orgId primitive.ObjectID, email string) (*models.UserAccount, error) {
+	return s.findUser(ctx, tenantFilter(orgId, bson.M{"email": email}))
+}
+
+func (s *MongoUserStore) ListUsers(ctx context.Context, orgId primitive.ObjectID) ([]models.UserAccount, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := s.collection.Find(ctx, tenantFilter(orgId, bson.M{}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %v", err)
+	}
+	users := []models.UserAccount{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode users: %v", err)
+	}
+	return users, nil
+}
+
+func (s *MongoUserStore) SetUserRole(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, role models.Role) (*models.UserAccount, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": bson.M{"role": role, "updatedAt": time.Now()}}
+
+	var user models.UserAccount
+	err := s.collection.FindOneAndUpdate(ctx, tenantFilter(orgId, bson.M{"_id": id}), update, opts).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update user: %v", err)
+	}
+	return &user, nil
+}
+
+func (s *MongoUserStore) findUser(ctx context.Context, filter bson.M) (*models.UserAccount, error) {
+	var user models.UserAccount
+	err := s.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+	return &user, nil
+}
+
+// MongoOrgStore stores organizations in a MongoDB collection.
+type MongoOrgStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoOrgStore(collection *mongo.Collection) *MongoOrgStore {
+	return &MongoOrgStore{collection: collection}
+}
+
+func (s *MongoOrgStore) CreateOrg(ctx context.Context, org *models.Organization) error {
+	if org.ID.IsZero() {
+		org.ID = primitive.NewObjectID()
+	}
+	if _, err := s.collection.InsertOne(ctx, org); err != nil {
+		return fmt.Errorf("failed to insert organization: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoOrgStore) GetOrg(ctx context.Context, id primitive.ObjectID) (*models.Organization, error) {
+	return s.findOrg(ctx, bson.M{"_id": id})
+}
+
+func (s *MongoOrgStore) GetOrgByAPIKey(ctx context.Context, keyHash string) (*models.Organization, error) {
+	return s.findOrg(ctx, bson.M{"apiKeys": bson.M{"$elemMatch": bson.M{
+		"hash":      keyHash,
+		"revokedAt": bson.M{"$exists": false},
+	}}})
+}
+
+func (s *MongoOrgStore) findOrg(ctx context.Context, filter bson.M) (*models.Organization, error) {
+	var org models.Organization
+	err := s.collection.FindOne(ctx, filter).Decode(&org)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch organization: %v", err)
+	}
+	return &org, nil
+}
+
+func (s *MongoOrgStore) ListOrgs(ctx context.Context) ([]models.Organization, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %v", err)
+	}
+	orgs := []models.Organization{}
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, fmt.Errorf("failed to decode organizations: %v", err)
+	}
+	return orgs, nil
+}
+
+func (s *MongoOrgStore) UpdateOrgConfig(ctx context.Context, id primitive.ObjectID, config models.OrgConfig) (*models.Organization, error) {
+	return s.updateOrg(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"config": config, "updatedAt": time.Now()}})
+}
+
+func (s *MongoOrgStore) AddAPIKey(ctx context.Context, id primitive.ObjectID, key models.APIKey) (*models.Organization, error) {
+	return s.updateOrg(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"apiKeys": key},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	})
+}
+
+func (s *MongoOrgStore) RevokeAPIKey(ctx context.Context, id primitive.ObjectID, keyId string, revokedAt time.Time) (*models.Organization, error) {
+	filter := bson.M{"_id": id, "apiKeys": bson.M{"$elemMatch": bson.M{
+		"id":        keyId,
+		"revokedAt": bson.M{"$exists": false},
+	}}}
+	return s.updateOrg(ctx, filter, bson.M{"$set": bson.M{"apiKeys.$.revokedAt": revokedAt, "updatedAt": revokedAt}})
+}
+
+func (s *MongoOrgStore) updateOrg(ctx context.Context, filter bson.M, update bson.M) (*models.Organization, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var org models.Organization
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&org)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update organization: %v", err)
+	}
+	return &org, nil
+}
+
+// MongoAuditStore stores audit events in a MongoDB collection.
+type MongoAuditStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAuditStore(collection *mongo.Collection) *MongoAuditStore {
+	return &MongoAuditStore{collection: collection}
+}
+
+func (s *MongoAuditStore) RecordEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	if _, err := s.collection.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to insert audit event: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoAuditStore) ListEvents(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.AuditEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := s.collection.Find(ctx, tenantFilter(orgId, bson.M{"sessionID": sessionId}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %v", err)
+	}
+	events := []models.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode audit events: %v", err)
+	}
+	return events, nil
+}
+
+// MongoNoteStore stores reviewer notes in a MongoDB collection.
+type MongoNoteStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoNoteStore(collection *mongo.Collection) *MongoNoteStore {
+	return &MongoNoteStore{collection: collection}
+}
+
+func (s *MongoNoteStore) AddNote(ctx context.Context, note *models.ReviewNote) error {
+	if note.ID.IsZero() {
+		note.ID = primitive.NewObjectID()
+	}
+	if _, err := s.collection.InsertOne(ctx, note); err != nil {
+		return fmt.Errorf("failed to insert note: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoNoteStore) ListNotes(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.ReviewNote, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := s.collection.Find(ctx, tenantFilter(orgId, bson.M{"sessionID": sessionId}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notes: %v", err)
+	}
+	notes := []models.ReviewNote{}
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, fmt.Errorf("failed to decode notes: %v", err)
+	}
+	return notes, nil
+}
+
+// MongoUsageStore stores usage records in a MongoDB collection.
+type MongoUsageStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoUsageStore(collection *mongo.Collection) *MongoUsageStore {
+	return &MongoUsageStore{collection: collection}
+}
+
+func (s *MongoUsageStore) InsertUsage(ctx context.Context, record models.UsageRecord) error {
+	if _, err := s.collection.InsertOne(ctx, record); err != nil {
+		return fmt.Errorf("failed to insert usage record: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoUsageStore) ListUsage(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.UsageRecord, error) {
+	opts := options.Find().SetSort(bson.M{"createdAt": 1})
+	cursor, err := s.collection.Find(ctx, tenantFilter(orgId, bson.M{"sessionid": sessionId}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch usage: %v", err)
+	}
+
+	records := []models.UsageRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode usage: %v", err)
+	}
+	return records, nil
+}
+
+func (s *MongoUsageStore) DailyUsage(ctx context.Context, filter UsageFilter) ([]models.DailyUsage, error) {
+	match := bson.M{}
+	if !filter.AllOrgs {
+		match = tenantFilter(filter.OrgID, match)
+	}
+	if filter.From != "" || filter.To != "" {
+		dayRange := bson.M{}
+		if filter.From != "" {
+			dayRange["$gte"] = filter.From
+		}
+		if filter.To != "" {
+			dayRange["$lte"] = filter.To
+		}
+		match["day"] = dayRange
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":             "$day",
+			"promptTokens":    bson.M{"$sum": "$promptTokens"},
+			"candidateTokens": bson.M{"$sum": "$candidateTokens"},
+			"totalTokens":     bson.M{"$sum": "$totalTokens"},
+			"estimatedCost":   bson.M{"$sum": "$estimatedCost"},
+			"calls":           bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage: %v", err)
+	}
+
+	days := []models.DailyUsage{}
+	if err := cursor.All(ctx, &days); err != nil {
+		return nil, fmt.Errorf("failed to decode usage: %v", err)
+	}
+	return days, nil
+}
+
+// MongoProgressStore aggregates progress from the sessions collection,
+// joining each session's turns from the questions collection.
+type MongoProgressStore struct {
+	sessions  *mongo.Collection
+	questions *mongo.Collection
+}
+
+func NewMongoProgressStore(sessions *mongo.Collection, questions *mongo.Collection) *MongoProgressStore {
+	return &MongoProgressStore{sessions: sessions, questions: questions}
+}
+
+// matchStage selects the user's sessions in the filter's window.
+func (s *MongoProgressStore) matchStage(filter ProgressFilter, extra bson.M) bson.D {
+	match := tenantFilter(filter.OrgID, extra)
+	match["userID"] = filter.UserID
+
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		match["createdAt"] = createdAt
+	}
+	return bson.D{{Key: "$match", Value: match}}
+}
+
+// periodExpr truncates a session's creation time to the filter's period.
+func periodExpr(period models.ProgressPeriod) bson.M {
+	return bson.M{"$dateTrunc": bson.M{
+		"date":        "$createdAt",
+		"unit":        string(period),
+		"startOfWeek": "monday",
+		"timezone":    "UTC",
+	}}
+}
+
+// oldestFirst orders sessions before they are grouped, so an area spelled
+// differently across sessions is named as in its oldest one.
+var oldestFirst = bson.D{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}}}
+
+// progressGroupStages group the unwound items by area and period, sorted by
+// area ignoring case and then by period. name is the area's display name;
+// sum is the question-weighted rating total and questions the number of
+// ratings.
+func progressGroupStages(name string, sum interface{}, questions interface{}) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":       bson.M{"area": bson.M{"$toLower": name}, "period": "$period"},
+			"area":      bson.M{"$first": name},
+			"sum":       bson.M{"$sum": sum},
+			"questions": bson.M{"$sum": questions},
+			"sessions":  bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"questions": bson.M{"$gt": 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.area", Value: 1}, {Key: "_id.period", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"area":      1,
+			"period":    "$_id.period",
+			"score":     bson.M{"$divide": bson.A{"$sum", "$questions"}},
+			"sessions":  1,
+			"questions": 1,
+		}}},
+	}
+}
+
+func (s *MongoProgressStore) StackProgress(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error) {
+	// A reviewer's rating replaces the AI one
+	effectiveRating := bson.M{"$ifNull": bson.A{"$$turn.review.rating", "$$turn.rating", nil}}
+
+	pipeline := mongo.Pipeline{
+		s.matchStage(filter, bson.M{"techStacks.0": bson.M{"$exists": true}}),
+		oldestFirst,
+		{{Key: "$lookup", Value: bson.M{
+			"from":         s.questions.Name(),
+			"localField":   "_id",
+			"foreignField": "sessionid",
+			"as":           "question",
+		}}},
+		{{Key: "$unwind", Value: "$question"}},
+		{{Key: "$project", Value: bson.M{
+			"techStacks": 1,
+			"period":     periodExpr(filter.Period),
+			"ratings": bson.M{"$filter": bson.M{
+				"input": bson.M{"$map": bson.M{"input": "$question.turns", "as": "turn", "in": effectiveRating}},
+				"as":    "rating",
+				"cond":  bson.M{"$ne": bson.A{"$$rating", nil}},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$techStacks"}},
+	}
+	pipeline = append(pipeline, progressGroupStages("$techStacks", bson.M{"$sum": "$ratings"}, bson.M{"$size": "$ratings"})...)
+
+	return s.aggregate(ctx, pipeline)
+}
+
+func (s *MongoProgressStore) TopicProgress(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error) {
+	pipeline := mongo.Pipeline{
+		s.matchStage(filter, bson.M{"report.topicScores.0": bson.M{"$exists": true}}),
+		oldestFirst,
+		{{Key: "$project", Value: bson.M{
+			"period": periodExpr(filter.Period),
+			"topic":  "$report.topicScores",
+		}}},
+		{{Key: "$unwind", Value: "$topic"}},
+	}
+	pipeline = append(pipeline, progressGroupStages(
+		"$topic.topic",
+		bson.M{"$multiply": bson.A{"$topic.score", "$topic.questions"}},
+		"$topic.questions",
+	)...)
+
+	return s.aggregate(ctx, pipeline)
+}
+
+func (s *MongoProgressStore) aggregate(ctx context.Context, pipeline mongo.Pipeline) ([]models.ProgressPoint, error) {
+	cursor, err := s.sessions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate progress: %v", err)
+	}
+	points := []models.ProgressPoint{}
+	if err := cursor.All(ctx, &points); err != nil {
+		return nil, fmt.Errorf("failed to decode progress: %v", err)
+	}
+	return points, nil
+}
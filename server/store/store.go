@@ -0,0 +1,358 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/db"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound is returned when the requested document does not exist, or
+// when a conditional update found nothing matching its preconditions.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when a document exists but no longer matches the
+// state an update expected.
+var ErrConflict = errors.New("conflict")
+
+// SessionCursor marks where a session listing stopped. Listings are sorted
+// newest first, with the ID breaking ties.
+type SessionCursor struct {
+	CreatedAt time.Time
+	ID        primitive.ObjectID
+}
+
+// SessionFilter selects sessions for ListSessions. Zero fields other than
+// OrgID match everything.
+type SessionFilter struct {
+	// OrgID is the tenant listed. It is always applied.
+	OrgID    primitive.ObjectID
+	UserID   primitive.ObjectID
+	Statuses []models.AllowedInterviewStatus
+	// TechStack matches sessions listing it, ignoring case.
+	TechStack     string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// After continues a previous listing from its last session.
+	After *SessionCursor
+	Limit int
+}
+
+// ExpiryCutoffs decide which sessions ExpireSessions expires. Paused
+// sessions are only subject to PausedBefore, since pausing freezes their
+// clock. A zero time disables that cutoff.
+type ExpiryCutoffs struct {
+	// InactiveBefore expires sessions last updated before it.
+	InactiveBefore time.Time
+	// StartedBefore expires sessions created before it, not counting the
+	// time they spent paused.
+	StartedBefore time.Time
+	// PausedBefore expires paused sessions paused before it.
+	PausedBefore time.Time
+}
+
+// IsStale reports whether session is past one of the cutoffs.
+func (c ExpiryCutoffs) IsStale(session *models.Session) bool {
+	if session.InterviewStatus == models.Paused {
+		return !c.PausedBefore.IsZero() && session.PausedAt != nil && session.PausedAt.Before(c.PausedBefore)
+	}
+	if !c.InactiveBefore.IsZero() && session.UpdatedAt.Before(c.InactiveBefore) {
+		return true
+	}
+	paused := time.Duration(session.PausedSeconds) * time.Second
+	return !c.StartedBefore.IsZero() && session.CreatedAt.Add(paused).Before(c.StartedBefore)
+}
+
+// SessionStore persists interview sessions.
+//
+// Every query is scoped to one tenant: orgId is the organization owning the
+// session, or primitive.NilObjectID for sessions outside any organization.
+// Sessions of other tenants are reported as not found.
+type SessionStore interface {
+	// CreateSession inserts session, owned by session.OrgID, and sets its ID.
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID) (*models.Session, error)
+	// ListSessions returns up to filter.Limit matching sessions of
+	// filter.OrgID, newest first.
+	ListSessions(ctx context.Context, filter SessionFilter) ([]models.Session, error)
+	// UpdateSession $sets fields (dotted paths allowed) and returns the
+	// updated session.
+	UpdateSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, fields bson.M) (*models.Session, error)
+	// TransitionSession sets the interview status to to, along with fields,
+	// only if the status is still from. It fails with ErrConflict otherwise.
+	TransitionSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, from models.AllowedInterviewStatus, to models.AllowedInterviewStatus, fields bson.M) (*models.Session, error)
+	// ExpireSessions moves every session whose status is in from and that
+	// is past one of the cutoffs to Expired, setting fields. It is the only
+	// query that spans every tenant.
+	ExpireSessions(ctx context.Context, from []models.AllowedInterviewStatus, cutoffs ExpiryCutoffs, fields bson.M) (int64, error)
+	// SaveReport stores report on the session unless it already has one,
+	// and returns the session with whichever report is stored.
+	SaveReport(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, report *models.Report) (*models.Session, error)
+	// ClaimSession moves the guest session whose guest token hashes to
+	// guestTokenHash into userId's account and revokes the guest token. It
+	// fails with ErrConflict if the session is no longer such a guest
+	// session.
+	ClaimSession(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, guestTokenHash string, userId primitive.ObjectID, claimedAt time.Time) (*models.Session, error)
+	// AddUsage adds usage to the session's running totals.
+	AddUsage(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, usage models.UsageTotals) error
+}
+
+// TurnStore persists the question document holding a session's turns. Like
+// SessionStore, every query is scoped to the tenant orgId.
+type TurnStore interface {
+	// CreateQuestion inserts the question document for a session, owned by
+	// question.OrgID. It fails with ErrConflict if the session already has
+	// one.
+	CreateQuestion(ctx context.Context, question *models.Question) error
+	GetQuestion(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) (*models.Question, error)
+	// AppendTurn stores answered in place of the latest turn and appends
+	// next after it. The turn numbers are checked in the same write: it
+	// fails with ErrConflict unless answered is still the latest turn.
+	AppendTurn(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, answered models.Turn, next models.Turn) (*models.Question, error)
+	// ReviewTurn stores a reviewer's override on turn number, replacing any
+	// earlier one. It fails with ErrNotFound if there is no such turn and
+	// with ErrConflict if the turn has not been rated yet.
+	ReviewTurn(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID, number int, review models.TurnReview) (*models.Question, error)
+	// ListReviewedTurns returns every turn of tenant orgId that a reviewer
+	// has overridden.
+	ListReviewedTurns(ctx context.Context, orgId primitive.ObjectID) ([]models.Turn, error)
+}
+
+// UserStore persists user accounts. Emails are unique within a tenant.
+type UserStore interface {
+	// CreateUser inserts user and sets its ID. It fails with ErrConflict if
+	// the email is already registered with user.OrgID.
+	CreateUser(ctx context.Context, user *models.UserAccount) error
+	GetUser(ctx context.Context, id primitive.ObjectID) (*models.UserAccount, error)
+	GetUserByEmail(ctx context.Context, orgId primitive.ObjectID, email string) (*models.UserAccount, error)
+	// ListUsers returns the accounts of tenant orgId, oldest first.
+	ListUsers(ctx context.Context, orgId primitive.ObjectID) ([]models.UserAccount, error)
+	SetUserRole(ctx context.Context, orgId primitive.ObjectID, id primitive.ObjectID, role models.Role) (*models.UserAccount, error)
+}
+
+// OrgStore persists organizations and their API keys.
+type OrgStore interface {
+	CreateOrg(ctx context.Context, org *models.Organization) error
+	GetOrg(ctx context.Context, id primitive.ObjectID) (*models.Organization, error)
+	// GetOrgByAPIKey returns the organization holding the unrevoked API key
+	// that hashes to keyHash.
+	GetOrgByAPIKey(ctx context.Context, keyHash string) (*models.Organization, error)
+	ListOrgs(ctx context.Context) ([]models.Organization, error)
+	UpdateOrgConfig(ctx context.Context, id primitive.ObjectID, config models.OrgConfig) (*models.Organization, error)
+	AddAPIKey(ctx context.Context, id primitive.ObjectID, key models.APIKey) (*models.Organization, error)
+	// RevokeAPIKey revokes an unrevoked key. It fails with ErrNotFound if
+	// the organization has no such key.
+	RevokeAPIKey(ctx context.Context, id primitive.ObjectID, keyId string, revokedAt time.Time) (*models.Organization, error)
+}
+
+// AuditStore is an append-only trail of audit events.
+type AuditStore interface {
+	RecordEvent(ctx context.Context, event *models.AuditEvent) error
+	// ListEvents returns the events of a session of tenant orgId, oldest
+	// first.
+	ListEvents(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.AuditEvent, error)
+}
+
+// ProgressFilter selects the sessions of one user that ProgressStore
+// aggregates. From and To bound their creation time and may be zero.
+type ProgressFilter struct {
+	OrgID  primitive.ObjectID
+	UserID primitive.ObjectID
+	From   time.Time
+	To     time.Time
+	Period models.ProgressPeriod
+}
+
+// ProgressStore aggregates a user's ratings across sessions. Each point
+// averages the ratings of the sessions created in its period, weighted by
+// the number of rated questions.
+type ProgressStore interface {
+	// StackProgress averages the effective turn ratings per tech stack the
+	// sessions listed.
+	StackProgress(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error)
+	// TopicProgress averages the topic scores of the sessions' reports.
+	TopicProgress(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error)
+}
+
+// NoteStore persists reviewer notes on sessions.
+type NoteStore interface {
+	AddNote(ctx context.Context, note *models.ReviewNote) error
+	// ListNotes returns the notes on a session of tenant orgId, oldest
+	// first.
+	ListNotes(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.ReviewNote, error)
+}
+
+// UsageFilter selects the records summed by DailyUsage. From and To are
+// inclusive YYYY-MM-DD bounds and may be empty.
+type UsageFilter struct {
+	From string
+	To   string
+	// OrgID limits the sum to one tenant unless AllOrgs is set.
+	OrgID   primitive.ObjectID
+	AllOrgs bool
+}
+
+// UsageStore persists per-call LLM usage records.
+type UsageStore interface {
+	InsertUsage(ctx context.Context, record models.UsageRecord) error
+	// ListUsage returns the records of a session of tenant orgId, oldest
+	// first.
+	ListUsage(ctx context.Context, orgId primitive.ObjectID, sessionId primitive.ObjectID) ([]models.UsageRecord, error)
+	// DailyUsage sums the matching usage per UTC day.
+	DailyUsage(ctx context.Context, filter UsageFilter) ([]models.DailyUsage, error)
+}
+
+// Stores bundles the stores used by the API.
+type Stores struct {
+	Backend  string
+	Sessions SessionStore
+	Turns    TurnStore
+	Users    UserStore
+	Orgs     OrgStore
+	Audit    AuditStore
+	Notes    NoteStore
+	Progress ProgressStore
+	// Usage is nil when usage records are not persisted.
+	Usage UsageStore
+
+	close func(ctx context.Context) error
+}
+
+// Close releases the backend's connections.
+func (s *Stores) Close(ctx context.Context) error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close(ctx)
+}
+
+// NewFromEnv builds the stores selected by STORE_BACKEND ("mongo" or
+// "memory"). Without STORE_BACKEND, MongoDB is used and MONGODB_URI must be
+// set; the in-memory store is only used when asked for, so a missing URI
+// never silently loses data.
+func NewFromEnv(ctx context.Context) (*Stores, error) {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORE_BACKEND")))
+	if backend == "" {
+		backend = "mongo"
+		if os.Getenv("MONGODB_URI") == "" {
+			return nil, fmt.Errorf("MONGODB_URI is not set; set STORE_BACKEND=memory to run with the in-memory store, whose data does not persist")
+		}
+	}
+
+	switch backend {
+	case "memory":
+		return NewMemoryStores(), nil
+	case "mongo":
+		return newMongoStoresFromEnv(ctx)
+	default:
+		return nil, fmt.Errorf("unknown STORE_BACKEND %q, expected 'mongo' or 'memory'", backend)
+	}
+}
+
+func newMongoStoresFromEnv(ctx context.Context) (*Stores, error) {
+	sessionColName := os.Getenv("SESSION_COLLECTION_NAME")
+	questionColName := os.Getenv("QUESTION_COLLECTION_NAME")
+	if sessionColName == "" || questionColName == "" {
+		return nil, fmt.Errorf("SESSION_COLLECTION_NAME and QUESTION_COLLECTION_NAME must be set")
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	database, err := db.Connect(connectCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionCollection := database.Collection(sessionColName)
+	questionCollection := database.Collection(questionColName)
+
+	userColName := os.Getenv("USER_COLLECTION_NAME")
+	if userColName == "" {
+		userColName = "users"
+	}
+	userCollection := database.Collection(userColName)
+
+	auditColName := os.Getenv("AUDIT_COLLECTION_NAME")
+	if auditColName == "" {
+		auditColName = "audit_events"
+	}
+	auditCollection := database.Collection(auditColName)
+
+	orgColName := os.Getenv("ORG_COLLECTION_NAME")
+	if orgColName == "" {
+		orgColName = "organizations"
+	}
+	orgCollection := database.Collection(orgColName)
+
+	noteColName := os.Getenv("NOTE_COLLECTION_NAME")
+	if noteColName == "" {
+		noteColName = "review_notes"
+	}
+	noteCollection := database.Collection(noteColName)
+
+	stores := &Stores{
+		Backend:  "mongo",
+		Sessions: NewMongoSessionStore(sessionCollection),
+		Turns:    NewMongoTurnStore(questionCollection),
+		Users:    NewMongoUserStore(userCollection),
+		Orgs:     NewMongoOrgStore(orgCollection),
+		Audit:    NewMongoAuditStore(auditCollection),
+		Notes:    NewMongoNoteStore(noteCollection),
+		Progress: NewMongoProgressStore(sessionCollection, questionCollection),
+		close:    db.Disconnect,
+	}
+
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		sessionCollection:  sessionIndexes,
+		questionCollection: questionIndexes,
+		userCollection:     userIndexes,
+		orgCollection:      orgIndexes,
+		auditCollection:    auditIndexes,
+		noteCollection:     noteIndexes,
+	}
+
+	if usageColName := os.Getenv("USAGE_COLLECTION_NAME"); usageColName == "" {
+		log.Println("Warning: USAGE_COLLECTION_NAME not set. Usage accounting will not be persisted.")
+	} else {
+		usageCollection := database.Collection(usageColName)
+		stores.Usage = NewMongoUsageStore(usageCollection)
+		indexes[usageCollection] = usageIndexes
+	}
+
+	// Convert question documents written before turns existed, and drop
+	// duplicates the unique sessionid index would reject
+	migrateCtx, cancelMigrate := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancelMigrate()
+	if _, err := db.MigrateQuestionTurns(migrateCtx, questionCollection); err != nil {
+		_ = db.Disconnect(context.Background())
+		return nil, fmt.Errorf("question migration failed: %v", err)
+	}
+	duplicateCollection := database.Collection(questionColName + "_duplicates")
+	if _, err := db.DedupeQuestions(migrateCtx, questionCollection, duplicateCollection); err != nil {
+		_ = db.Disconnect(context.Background())
+		return nil, fmt.Errorf("question deduplication failed: %v", err)
+	}
+
+	// The stores rely on these indexes for uniqueness, so a missing one is
+	// fatal rather than a slow query
+	indexCtx, cancelIndex := context.WithTimeout(ctx, time.Minute)
+	defer cancelIndex()
+	for collection, indexModels := range indexes {
+		if err := db.EnsureIndexes(indexCtx, collection, indexModels); err != nil {
+			_ = db.Disconnect(context.Background())
+			return nil, err
+		}
+	}
+
+	return stores, nil
+}
@@ -0,0 +1,518 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rnkp755/mockinterviewBackend/db"
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// conformanceStores are the stores under test, fresh for every test.
+type conformanceStores struct {
+	sessions SessionStore
+	turns    TurnStore
+	audit    AuditStore
+	usage    UsageStore
+	progress ProgressStore
+}
+
+func TestMemoryStoreConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) conformanceStores {
+		sessions, turns := NewMemorySessionStore(), NewMemoryTurnStore()
+		return conformanceStores{
+			sessions: sessions,
+			turns:    turns,
+			audit:    NewMemoryAuditStore(),
+			usage:    NewMemoryUsageStore(),
+			progress: NewMemoryProgressStore(sessions, turns),
+		}
+	})
+}
+
+// TestMongoStoreConformance runs against the MongoDB at MONGODB_TEST_URI,
+// in a throwaway database per test.
+func TestMongoStoreConformance(t *testing.T) {
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connecting to MongoDB: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	runConformance(t, func(t *testing.T) conformanceStores {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		database := client.Database("conformance_" + primitive.NewObjectID().Hex())
+		t.Cleanup(func() { database.Drop(context.Background()) })
+
+		sessions := database.Collection("sessions")
+		questions := database.Collection("questions")
+		audit := database.Collection("audit_events")
+		usage := database.Collection("usage")
+		for collection, indexes := range map[*mongo.Collection][]mongo.IndexModel{
+			sessions:  sessionIndexes,
+			questions: questionIndexes,
+			audit:     auditIndexes,
+			usage:     usageIndexes,
+		} {
+			if err := db.EnsureIndexes(ctx, collection, indexes); err != nil {
+				t.Fatalf("creating indexes: %v", err)
+			}
+		}
+		return conformanceStores{
+			sessions: NewMongoSessionStore(sessions),
+			turns:    NewMongoTurnStore(questions),
+			audit:    NewMongoAuditStore(audit),
+			usage:    NewMongoUsageStore(usage),
+			progress: NewMongoProgressStore(sessions, questions),
+		}
+	})
+}
+
+func runConformance(t *testing.T, newStores func(t *testing.T) conformanceStores) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, stores conformanceStores)
+	}{
+		{"sessions are scoped to their tenant", testSessionTenants},
+		{"TransitionSession only moves from the expected status", testTransitionSession},
+		{"ExpireSessions expires stale sessions of every tenant", testExpireSessions},
+		{"question documents are scoped to their tenant", testQuestionTenants},
+		{"AppendTurn only appends after the latest turn", testAppendTurn},
+		{"audit events and usage are scoped to their tenant", testSessionRecordTenants},
+		{"progress is grouped by area and period within the window", testProgress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStores(t))
+		})
+	}
+}
+
+// testContext bounds one store call.
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// createTestSession stores a session of tenant orgId with the given status
+// and times, rounded to what MongoDB stores.
+func createTestSession(t *testing.T, sessions SessionStore, orgId primitive.ObjectID, status models.AllowedInterviewStatus, createdAt time.Time, updatedAt time.Time) *models.Session {
+	t.Helper()
+
+	session := &models.Session{
+		OrgID:           orgId,
+		UserType:        models.Guest,
+		TechStacks:      []string{"Go"},
+		InterviewStatus: status,
+		CreatedAt:       createdAt.Truncate(time.Millisecond),
+		UpdatedAt:       updatedAt.Truncate(time.Millisecond),
+	}
+	if err := sessions.CreateSession(testContext(t), session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	return session
+}
+
+func wantErr(t *testing.T, call string, err error, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s: got error %v, want %v", call, err, want)
+	}
+}
+
+func testSessionTenants(t *testing.T, stores conformanceStores) {
+	orgA, orgB := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Now()
+	inA := createTestSession(t, stores.sessions, orgA, models.NotStarted, now, now)
+	outside := createTestSession(t, stores.sessions, primitive.NilObjectID, models.NotStarted, now, now)
+
+	if got, err := stores.sessions.GetSession(testContext(t), orgA, inA.ID); err != nil || got.ID != inA.ID {
+		t.Fatalf("GetSession in its own tenant: got %v, %v", got, err)
+	}
+	if _, err := stores.sessions.GetSession(testContext(t), primitive.NilObjectID, outside.ID); err != nil {
+		t.Fatalf("GetSession outside any organization: %v", err)
+	}
+
+	for _, orgId := range []primitive.ObjectID{orgB, primitive.NilObjectID} {
+		_, err := stores.sessions.GetSession(testContext(t), orgId, inA.ID)
+		wantErr(t, "GetSession from another tenant", err, ErrNotFound)
+		_, err = stores.sessions.UpdateSession(testContext(t), orgId, inA.ID, bson.M{"name": "intruder"})
+		wantErr(t, "UpdateSession from another tenant", err, ErrNotFound)
+		_, err = stores.sessions.TransitionSession(testContext(t), orgId, inA.ID, models.NotStarted, models.Evaluating, nil)
+		wantErr(t, "TransitionSession from another tenant", err, ErrNotFound)
+	}
+	_, err := stores.sessions.GetSession(testContext(t), orgA, outside.ID)
+	wantErr(t, "GetSession of a session outside any organization", err, ErrNotFound)
+
+	listed, err := stores.sessions.ListSessions(testContext(t), SessionFilter{OrgID: orgB})
+	if err != nil || len(listed) != 0 {
+		t.Errorf("ListSessions of an empty tenant: got %d sessions, %v", len(listed), err)
+	}
+	listed, err = stores.sessions.ListSessions(testContext(t), SessionFilter{OrgID: orgA})
+	if err != nil || len(listed) != 1 || listed[0].ID != inA.ID {
+		t.Errorf("ListSessions of tenant A: got %d sessions, %v", len(listed), err)
+	}
+
+	// The intruders changed nothing
+	got, err := stores.sessions.GetSession(testContext(t), orgA, inA.ID)
+	if err != nil || got.Name != "" || got.InterviewStatus != models.NotStarted {
+		t.Errorf("session after updates from other tenants: got %+v, %v", got, err)
+	}
+}
+
+func testTransitionSession(t *testing.T, stores conformanceStores) {
+	orgId := primitive.NewObjectID()
+	now := time.Now()
+	session := createTestSession(t, stores.sessions, orgId, models.NotStarted, now, now)
+
+	updated, err := stores.sessions.TransitionSession(testContext(t), orgId, session.ID, models.NotStarted, models.Evaluating, bson.M{"ability": 2.5})
+	if err != nil {
+		t.Fatalf("TransitionSession: %v", err)
+	}
+	if updated.InterviewStatus != models.Evaluating || updated.Ability != 2.5 {
+		t.Errorf("TransitionSession returned status %q and ability %v, want %q and 2.5", updated.InterviewStatus, updated.Ability, models.Evaluating)
+	}
+
+	// A second request that read the old status loses the race
+	_, err = stores.sessions.TransitionSession(testContext(t), orgId, session.ID, models.NotStarted, models.Evaluating, bson.M{"ability": 4.0})
+	wantErr(t, "TransitionSession from a stale status", err, ErrConflict)
+
+	_, err = stores.sessions.TransitionSession(testContext(t), orgId, primitive.NewObjectID(), models.NotStarted, models.Evaluating, nil)
+	wantErr(t, "TransitionSession of a missing session", err, ErrNotFound)
+
+	stored, err := stores.sessions.GetSession(testContext(t), orgId, session.ID)
+	if err != nil || stored.InterviewStatus != models.Evaluating || stored.Ability != 2.5 {
+		t.Errorf("stored session: got %+v, %v", stored, err)
+	}
+}
+
+func testExpireSessions(t *testing.T, stores conformanceStores) {
+	now := time.Now()
+	hourAgo, dayAgo := now.Add(-time.Hour), now.Add(-24*time.Hour)
+	orgA, orgB := primitive.NewObjectID(), primitive.NewObjectID()
+
+	inactive := createTestSession(t, stores.sessions, orgA, models.WaitingForAnswer, dayAgo, dayAgo)
+	otherTenant := createTestSession(t, stores.sessions, orgB, models.WaitingForAnswer, dayAgo, dayAgo)
+	active := createTestSession(t, stores.sessions, orgA, models.WaitingForAnswer, hourAgo, now)
+	ended := createTestSession(t, stores.sessions, orgA, models.Ended, dayAgo, dayAgo)
+
+	// Paused sessions only expire by how long they have been paused
+	pausedLong := createTestSession(t, stores.sessions, orgA, models.Paused, dayAgo, dayAgo)
+	pausedRecently := createTestSession(t, stores.sessions, orgA, models.Paused, dayAgo, dayAgo)
+	for session, pausedAt := range map[*models.Session]time.Time{pausedLong: dayAgo, pausedRecently: now} {
+		if _, err := stores.sessions.UpdateSession(testContext(t), orgA, session.ID, bson.M{"pausedAt": pausedAt.Truncate(time.Millisecond)}); err != nil {
+			t.Fatalf("UpdateSession: %v", err)
+		}
+	}
+
+	// Time spent paused does not count towards the maximum age
+	old := createTestSession(t, stores.sessions, orgA, models.WaitingForAnswer, dayAgo, now)
+	oldButPaused := createTestSession(t, stores.sessions, orgA, models.WaitingForAnswer, dayAgo, now)
+	if _, err := stores.sessions.UpdateSession(testContext(t), orgA, oldButPaused.ID, bson.M{"pausedSeconds": int64(23 * 60 * 60)}); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+
+	expiredAt := now.Truncate(time.Millisecond)
+	count, err := stores.sessions.ExpireSessions(testContext(t),
+		[]models.AllowedInterviewStatus{models.NotStarted, models.WaitingForAnswer, models.Paused},
+		ExpiryCutoffs{
+			InactiveBefore: now.Add(-2 * time.Hour),
+			StartedBefore:  now.Add(-12 * time.Hour),
+			PausedBefore:   now.Add(-2 * time.Hour),
+		},
+		bson.M{"hasExpired": true, "expiredAt": expiredAt})
+	if err != nil {
+		t.Fatalf("ExpireSessions: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("ExpireSessions expired %d sessions, want 4", count)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		session *models.Session
+		want    models.AllowedInterviewStatus
+	}{
+		{"inactive", inactive, models.Expired},
+		{"inactive in another tenant", otherTenant, models.Expired},
+		{"active", active, models.WaitingForAnswer},
+		{"ended", ended, models.Ended},
+		{"paused long ago", pausedLong, models.Expired},
+		{"paused recently", pausedRecently, models.Paused},
+		{"past the maximum age", old, models.Expired},
+		{"past the maximum age only counting pauses", oldButPaused, models.WaitingForAnswer},
+	} {
+		got, err := stores.sessions.GetSession(testContext(t), tc.session.OrgID, tc.session.ID)
+		if err != nil {
+			t.Fatalf("GetSession %s: %v", tc.name, err)
+		}
+		if got.InterviewStatus != tc.want {
+			t.Errorf("%s session: status %q, want %q", tc.name, got.InterviewStatus, tc.want)
+		}
+		if expired := tc.want == models.Expired; got.HasExpired != expired || (got.ExpiredAt != nil) != expired {
+			t.Errorf("%s session: hasExpired %v, expiredAt %v", tc.name, got.HasExpired, got.ExpiredAt)
+		}
+	}
+}
+
+// newTestQuestion returns the question document of a session whose first
+// question was asked.
+func newTestQuestion(orgId primitive.ObjectID, sessionId primitive.ObjectID) *models.Question {
+	return &models.Question{
+		OrgID:     orgId,
+		SessionId: sessionId,
+		Turns:     []models.Turn{{Number: 1, Question: "What is a goroutine?"}},
+	}
+}
+
+func testQuestionTenants(t *testing.T, stores conformanceStores) {
+	orgA, orgB := primitive.NewObjectID(), primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+	if err := stores.turns.CreateQuestion(testContext(t), newTestQuestion(orgA, sessionId)); err != nil {
+		t.Fatalf("CreateQuestion: %v", err)
+	}
+
+	if _, err := stores.turns.GetQuestion(testContext(t), orgA, sessionId); err != nil {
+		t.Fatalf("GetQuestion in its own tenant: %v", err)
+	}
+	for _, orgId := range []primitive.ObjectID{orgB, primitive.NilObjectID} {
+		_, err := stores.turns.GetQuestion(testContext(t), orgId, sessionId)
+		wantErr(t, "GetQuestion from another tenant", err, ErrNotFound)
+		_, err = stores.turns.AppendTurn(testContext(t), orgId, sessionId, models.Turn{Number: 1}, models.Turn{Number: 2})
+		wantErr(t, "AppendTurn from another tenant", err, ErrNotFound)
+	}
+
+	// A session has one question document, whatever tenant asks for another
+	err := stores.turns.CreateQuestion(testContext(t), newTestQuestion(orgB, sessionId))
+	wantErr(t, "CreateQuestion for a session that has one", err, ErrConflict)
+}
+
+func testAppendTurn(t *testing.T, stores conformanceStores) {
+	orgId := primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+	if err := stores.turns.CreateQuestion(testContext(t), newTestQuestion(orgId, sessionId)); err != nil {
+		t.Fatalf("CreateQuestion: %v", err)
+	}
+
+	rating := 7
+	answered := models.Turn{Number: 1, Question: "What is a goroutine?", Rating: &rating}
+	next := models.Turn{Number: 2, Question: "What is a channel?"}
+	question, err := stores.turns.AppendTurn(testContext(t), orgId, sessionId, answered, next)
+	if err != nil {
+		t.Fatalf("AppendTurn: %v", err)
+	}
+	if len(question.Turns) != 2 || question.Turns[0].Rating == nil || *question.Turns[0].Rating != 7 || question.Turns[1].Question != next.Question {
+		t.Fatalf("AppendTurn returned %+v", question.Turns)
+	}
+
+	// The same turn answered twice, e.g. by two concurrent requests
+	_, err = stores.turns.AppendTurn(testContext(t), orgId, sessionId, answered, models.Turn{Number: 2, Question: "Duplicate"})
+	wantErr(t, "AppendTurn of an answered turn", err, ErrConflict)
+
+	// A turn that was never asked
+	_, err = stores.turns.AppendTurn(testContext(t), orgId, sessionId, models.Turn{Number: 3}, models.Turn{Number: 4})
+	wantErr(t, "AppendTurn past the latest turn", err, ErrConflict)
+
+	_, err = stores.turns.AppendTurn(testContext(t), orgId, primitive.NewObjectID(), answered, next)
+	wantErr(t, "AppendTurn without a question document", err, ErrNotFound)
+
+	stored, err := stores.turns.GetQuestion(testContext(t), orgId, sessionId)
+	if err != nil || len(stored.Turns) != 2 || stored.Turns[1].Question != next.Question {
+		t.Errorf("stored turns: got %+v, %v", stored, err)
+	}
+}
+
+func testSessionRecordTenants(t *testing.T, stores conformanceStores) {
+	orgA, orgB := primitive.NewObjectID(), primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+	now := time.Now().Truncate(time.Millisecond)
+
+	// A session ID borrowed by another tenant must not mix the records
+	for _, orgId := range []primitive.ObjectID{orgA, orgB, primitive.NilObjectID} {
+		event := &models.AuditEvent{OrgID: orgId, Action: models.AuditSessionClaimed, SessionID: sessionId, CreatedAt: now}
+		if err := stores.audit.RecordEvent(testContext(t), event); err != nil {
+			t.Fatalf("RecordEvent: %v", err)
+		}
+		record := models.UsageRecord{ID: primitive.NewObjectID(), OrgID: orgId, SessionId: sessionId, TotalTokens: 10, CreatedAt: now}
+		if err := stores.usage.InsertUsage(testContext(t), record); err != nil {
+			t.Fatalf("InsertUsage: %v", err)
+		}
+	}
+
+	for _, orgId := range []primitive.ObjectID{orgA, primitive.NilObjectID} {
+		events, err := stores.audit.ListEvents(testContext(t), orgId, sessionId)
+		if err != nil || len(events) != 1 || events[0].OrgID != orgId {
+			t.Errorf("ListEvents of tenant %s: got %+v, %v", orgId.Hex(), events, err)
+		}
+		records, err := stores.usage.ListUsage(testContext(t), orgId, sessionId)
+		if err != nil || len(records) != 1 || records[0].OrgID != orgId {
+			t.Errorf("ListUsage of tenant %s: got %+v, %v", orgId.Hex(), records, err)
+		}
+	}
+
+	events, err := stores.audit.ListEvents(testContext(t), primitive.NewObjectID(), sessionId)
+	if err != nil || len(events) != 0 {
+		t.Errorf("ListEvents of an unrelated tenant: got %d events, %v", len(events), err)
+	}
+	records, err := stores.usage.ListUsage(testContext(t), primitive.NewObjectID(), sessionId)
+	if err != nil || len(records) != 0 {
+		t.Errorf("ListUsage of an unrelated tenant: got %d records, %v", len(records), err)
+	}
+}
+
+// progressSession is a session of the progress tests and its turns.
+type progressSession struct {
+	orgId     primitive.ObjectID
+	userId    primitive.ObjectID
+	createdAt time.Time
+	stacks    []string
+	ratings   []*int
+	topics    []models.TopicScore
+}
+
+func testProgress(t *testing.T, stores conformanceStores) {
+	orgId, userId := primitive.NewObjectID(), primitive.NewObjectID()
+	rating := func(r int) *int { return &r }
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 10, 0, 0, 0, time.UTC) }
+
+	// 2 March 2026 is a Monday
+	sessions := []progressSession{
+		{orgId, userId, day(time.March, 3), []string{"Go", "React"}, []*int{rating(6), rating(8)},
+			[]models.TopicScore{{Topic: "Concurrency", Score: 7, Questions: 2}}},
+		{orgId, userId, day(time.March, 5), []string{"go"}, []*int{rating(9), nil},
+			[]models.TopicScore{{Topic: "concurrency", Score: 9, Questions: 1}}},
+		{orgId, userId, day(time.March, 10), []string{"GO"}, []*int{rating(5)},
+			[]models.TopicScore{{Topic: "CONCURRENCY", Score: 5, Questions: 1}}},
+		// Outside the window, the second one exactly at its exclusive end
+		{orgId, userId, day(time.February, 20), []string{"Go"}, []*int{rating(10)},
+			[]models.TopicScore{{Topic: "Concurrency", Score: 10, Questions: 1}}},
+		{orgId, userId, time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC), []string{"Go"}, []*int{rating(10)},
+			[]models.TopicScore{{Topic: "Concurrency", Score: 10, Questions: 1}}},
+		// Another user, and the same user ID in another tenant
+		{orgId, primitive.NewObjectID(), day(time.March, 4), []string{"Go"}, []*int{rating(0)},
+			[]models.TopicScore{{Topic: "Concurrency", Score: 0, Questions: 1}}},
+		{primitive.NewObjectID(), userId, day(time.March, 4), []string{"Go"}, []*int{rating(0)},
+			[]models.TopicScore{{Topic: "Concurrency", Score: 0, Questions: 1}}},
+		// Nothing rated yet
+		{orgId, userId, day(time.March, 6), []string{"Rust"}, []*int{nil}, nil},
+	}
+	for _, s := range sessions {
+		session := &models.Session{
+			OrgID:           s.orgId,
+			UserID:          s.userId,
+			UserType:        models.User,
+			TechStacks:      s.stacks,
+			InterviewStatus: models.Ended,
+			CreatedAt:       s.createdAt,
+			UpdatedAt:       s.createdAt,
+		}
+		if s.topics != nil {
+			session.Report = &models.Report{TopicScores: s.topics}
+		}
+		if err := stores.sessions.CreateSession(testContext(t), session); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+
+		question := &models.Question{OrgID: s.orgId, SessionId: session.ID}
+		for i, r := range s.ratings {
+			question.Turns = append(question.Turns, models.Turn{Number: i + 1, Rating: r})
+		}
+		// A reviewer's rating replaces the AI one
+		if s.createdAt.Equal(day(time.March, 5)) {
+			question.Turns[0].Rating = rating(4)
+			question.Turns[0].Review = &models.TurnReview{Rating: 9}
+		}
+		if err := stores.turns.CreateQuestion(testContext(t), question); err != nil {
+			t.Fatalf("CreateQuestion: %v", err)
+		}
+	}
+
+	week := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	window := ProgressFilter{
+		OrgID:  orgId,
+		UserID: userId,
+		From:   time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC),
+		Period: models.ProgressWeek,
+	}
+	monthly := window
+	monthly.Period = models.ProgressMonth
+
+	tests := []struct {
+		name      string
+		aggregate func(ctx context.Context, filter ProgressFilter) ([]models.ProgressPoint, error)
+		filter    ProgressFilter
+		want      []models.ProgressPoint
+	}{
+		{
+			name:      "stacks per week",
+			aggregate: stores.progress.StackProgress,
+			filter:    window,
+			want: []models.ProgressPoint{
+				{Area: "Go", Period: week(2), Score: 23.0 / 3, Sessions: 2, Questions: 3},
+				{Area: "GO", Period: week(9), Score: 5, Sessions: 1, Questions: 1},
+				{Area: "React", Period: week(2), Score: 7, Sessions: 1, Questions: 2},
+			},
+		},
+		{
+			name:      "stacks per month",
+			aggregate: stores.progress.StackProgress,
+			filter:    monthly,
+			want: []models.ProgressPoint{
+				{Area: "Go", Period: week(1), Score: 7, Sessions: 3, Questions: 4},
+				{Area: "React", Period: week(1), Score: 7, Sessions: 1, Questions: 2},
+			},
+		},
+		{
+			name:      "topics per week",
+			aggregate: stores.progress.TopicProgress,
+			filter:    window,
+			want: []models.ProgressPoint{
+				{Area: "Concurrency", Period: week(2), Score: 23.0 / 3, Sessions: 2, Questions: 3},
+				{Area: "CONCURRENCY", Period: week(9), Score: 5, Sessions: 1, Questions: 1},
+			},
+		},
+		{
+			name:      "nothing in the window",
+			aggregate: stores.progress.TopicProgress,
+			filter:    ProgressFilter{OrgID: orgId, UserID: userId, From: week(20), Period: models.ProgressWeek},
+			want:      []models.ProgressPoint{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.aggregate(testContext(t), tt.filter)
+			if err != nil {
+				t.Fatalf("aggregating progress: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d points %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				point := got[i]
+				if point.Area != want.Area || !point.Period.Equal(want.Period) || point.Sessions != want.Sessions ||
+					point.Questions != want.Questions || math.Abs(point.Score-want.Score) > 1e-9 {
+					t.Errorf("point %d = %+v, want %+v", i, point, want)
+				}
+			}
+		})
+	}
+}
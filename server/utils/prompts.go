@@ -8,6 +8,11 @@ import (
 	"github.com/rnkp755/mockinterviewBackend/models"
 )
 
+// PromptVersion identifies the interviewer prompt and rating rubric. It is
+// stored with every AI rating so the grader's agreement with human reviewers
+// can be compared across prompt changes; bump it whenever they change.
+const PromptVersion = "v2"
+
 // Define constants for static parts of the prompt to avoid re-allocation
 const (
 	persona = `You are Vandana, an experienced Technical Interviewer at Google. 
@@ -15,18 +20,16 @@ Your role is to evaluate candidates by diving into their technical expertise, da
 Maintain a professional tone.
 `
 
-	// XML Format instructions
+	// JSON output instructions. The matching response schemas live in schemas.go.
 	constaintFirstQuestion = `
 <StrictConstraints>
 1. You must start with a Greeting (Current Time: %s).
-2. Ask the first technical question based on the candidate's stack.
-3. Your output must strictly follow this XML format (no markdown outside tags):
-<Question>
-{Greeting message and the First Question}
-</Question>
-<Code>
-{Optional: Only if you need to provide a code snippet for the question, otherwise leave empty}
-</Code>
+2. Ask the first technical question based on the candidate's stack, at the level given in <Difficulty>.
+3. Respond with a single JSON object and nothing else:
+{
+  "question": "{Greeting message and the First Question}",
+  "code": "{Optional: Only if you need to provide a code snippet for the question, otherwise an empty string}"
+}
 </StrictConstraints>
 `
 
@@ -35,17 +38,51 @@ Maintain a professional tone.
 1. Evaluate the candidate's answer to the <CurrentQuestion> provided above.
 2. Provide a Rating out of 10.
 3. Provide constructive Feedback (Positive, Negative, Improvements).
-4. Ask the Next Question. 
+4. Ask the Next Question at the level that <Difficulty> gives for the rating you chose.
 5. If the user's answer was extremely poor or irrelevant, give a low rating.
-6. Your output must strictly follow this XML format (no markdown outside tags):
-<Rating>{Integer 0-10}</Rating>
-<Feedback>
-  <Positive>{What they did right}</Positive>
-  <Negative>{What they did wrong}</Negative>
-  <Improvements>{How to optimize}</Improvements>
-</Feedback>
-<Question>{The Next Question}</Question>
-<Code>{Optional: Code snippet for the next question if needed}</Code>
+6. Respond with a single JSON object and nothing else:
+{
+  "rating": {Integer 0-10},
+  "feedback": {
+    "positive": "{What they did right}",
+    "negative": "{What they did wrong}",
+    "improvements": "{How to optimize}"
+  },
+  "question": "{The Next Question}",
+  "code": "{Optional: Code snippet for the next question if needed, otherwise an empty string}"
+}
+</StrictConstraints>
+`
+
+	constraintReport = `
+<StrictConstraints>
+1. The interview above is over. Write the final report for the candidate.
+2. Give every evaluated <Turn> a short topic name (for example one of the candidate's TechStacks, "Data Structures", "Algorithms" or "System Design"). Reuse the same name for questions on the same topic.
+3. List the candidate's strengths and weaknesses, citing the questions they come from.
+4. Give an ordered improvement roadmap of concrete next steps.
+5. Recommend one of: strong-hire, hire, lean-hire, lean-no-hire, no-hire. Base it on the ratings given.
+6. Respond with a single JSON object and nothing else:
+{
+  "turnTopics": [{"turn": {Question number}, "topic": "{Topic}"}],
+  "strengths": ["{Strength}"],
+  "weaknesses": ["{Weakness}"],
+  "roadmap": ["{Next step}"],
+  "recommendation": "{Recommendation}",
+  "summary": "{Two or three sentence summary}"
+}
+</StrictConstraints>
+`
+
+	constraintWelcomeBack = `
+<StrictConstraints>
+1. The candidate paused the interview and has just come back.
+2. Welcome them back and give a short recap (one or two sentences) of how the interview has gone so far.
+3. Repeat the <CurrentQuestion> so they can answer it. Do not ask a new question and do not evaluate anything.
+4. Respond with a single JSON object and nothing else:
+{
+  "question": "{Welcome back message, the recap and the current question}",
+  "code": "{The current question's code snippet unchanged, otherwise an empty string}"
+}
 </StrictConstraints>
 `
 )
@@ -62,11 +99,94 @@ Projects: %v
 `, session.Name, session.Experience, session.TechStacks, session.Projects)
 }
 
+// buildFirstDifficulty tells the model the level of the first question
+func buildFirstDifficulty(plan models.DifficultyPlan) string {
+	return fmt.Sprintf(`
+<Difficulty scale="%d-%d">
+Ask the first question at level %d (%s).
+</Difficulty>
+`, models.MinDifficulty, models.MaxDifficulty, plan.Current, models.DifficultyLabel(plan.Current))
+}
+
+// buildNextDifficulty tells the model the level of the next question for
+// every rating it may give the current answer
+func buildNextDifficulty(plan models.DifficultyPlan) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n<Difficulty scale=\"%d-%d\">\n", models.MinDifficulty, models.MaxDifficulty))
+	sb.WriteString(fmt.Sprintf("The current question was asked at level %d (%s).\n", plan.Current, models.DifficultyLabel(plan.Current)))
+	sb.WriteString("Pick the level of the next question from the rating you give:\n")
+	for _, step := range plan.Steps() {
+		change := "keep the same level"
+		if step.Difficulty < plan.Current {
+			change = "make it easier"
+		} else if step.Difficulty > plan.Current {
+			change = "make it harder"
+		}
+		ratings := fmt.Sprintf("%d-%d", step.MinRating, step.MaxRating)
+		if step.MinRating == step.MaxRating {
+			ratings = fmt.Sprintf("%d", step.MinRating)
+		}
+		sb.WriteString(fmt.Sprintf("- Rating %s: level %d (%s), %s\n",
+			ratings, step.Difficulty, models.DifficultyLabel(step.Difficulty), change))
+	}
+	sb.WriteString("</Difficulty>\n")
+	return sb.String()
+}
+
+// buildTurnHistory renders one answered turn for the <History> block
+func buildTurnHistory(turn *models.Turn) string {
+	var sb strings.Builder
+	if turn.Difficulty != 0 {
+		sb.WriteString(fmt.Sprintf("<Turn number=\"%d\" difficulty=\"%d\">\n", turn.Number, turn.Difficulty))
+	} else {
+		sb.WriteString(fmt.Sprintf("<Turn number=\"%d\">\n", turn.Number))
+	}
+	sb.WriteString(fmt.Sprintf("  <QuestionAsked>%s</QuestionAsked>\n", turn.Question))
+	if turn.Code != "" {
+		sb.WriteString(fmt.Sprintf("  <Code>%s</Code>\n", turn.Code))
+	}
+	if turn.Answer != nil {
+		sb.WriteString(fmt.Sprintf("  <CandidateAnswer source=%q>%s</CandidateAnswer>\n", turn.Answer.Source, turn.Answer.Text))
+	}
+	if rating := turn.EffectiveRating(); rating != nil {
+		sb.WriteString(fmt.Sprintf("  <RatingGiven>%d</RatingGiven>\n", *rating))
+	}
+	if feedback := turn.EffectiveFeedback(); feedback != nil {
+		sb.WriteString("  <FeedbackGiven>\n")
+		sb.WriteString(fmt.Sprintf("    <Positive>%s</Positive>\n", feedback.Positive))
+		sb.WriteString(fmt.Sprintf("    <Negative>%s</Negative>\n", feedback.Negative))
+		sb.WriteString(fmt.Sprintf("    <Improvements>%s</Improvements>\n", feedback.Improvements))
+		sb.WriteString("  </FeedbackGiven>\n")
+	}
+	sb.WriteString("</Turn>\n")
+	return sb.String()
+}
+
+// buildHistory renders every answered turn, i.e. all but the current one
+func buildHistory(questions *models.Question) string {
+	var sb strings.Builder
+	sb.WriteString("<History>\n")
+	for _, turn := range questions.Turns[:len(questions.Turns)-1] {
+		sb.WriteString(buildTurnHistory(&turn))
+	}
+	sb.WriteString("</History>\n")
+	return sb.String()
+}
+
+// personaFor returns the organization's persona the session was created
+// with, or the default one.
+func personaFor(session *models.Session) string {
+	if session.Persona == "" {
+		return persona
+	}
+	return session.Persona + "\n"
+}
+
 func PromptGenerator(session *models.Session, questions *models.Question, answer string) string {
 	var sb strings.Builder
 
 	// 1. Add System Persona
-	sb.WriteString(persona)
+	sb.WriteString(personaFor(session))
 
 	// 2. Add Candidate Details
 	sb.WriteString(buildCandidateDetails(session))
@@ -74,7 +194,9 @@ func PromptGenerator(session *models.Session, questions *models.Question, answer
 	// 3. Handle Logic based on Interview Status
 	if session.InterviewStatus == models.NotStarted {
 		// --- First Question Flow ---
-		
+
+		sb.WriteString(buildFirstDifficulty(models.PlanDifficulty(session, questions)))
+
 		// Inject dynamic time into the constraint
 		currentTime := time.Now().Format("15:04")
 		sb.WriteString(fmt.Sprintf(constaintFirstQuestion, currentTime))
@@ -83,36 +205,78 @@ func PromptGenerator(session *models.Session, questions *models.Question, answer
 		// --- Follow-up Question Flow ---
 
 		// A. Add Context (Previous Q&A History)
-		// We iterate up to len-1 because the last question is the "Current" one being answered
-		if questions != nil && len(questions.Question) > 0 {
-			sb.WriteString("<History>\n")
-			for i := 0; i < len(questions.Question)-1; i++ {
-				sb.WriteString("<Turn>\n")
-				sb.WriteString(fmt.Sprintf("  <QuestionAsked>%s</QuestionAsked>\n", questions.Question[i]))
-				
-				// Safety check to ensure we don't crash if arrays are uneven length
-				if i < len(questions.Rating) {
-					sb.WriteString(fmt.Sprintf("  <RatingGiven>%s</RatingGiven>\n", questions.Rating[i]))
-				}
-				if i < len(questions.Review) {
-					// Assuming Review stores the feedback text
-					sb.WriteString(fmt.Sprintf("  <FeedbackGiven>%s</FeedbackGiven>\n", questions.Review[i])) 
-				}
-				sb.WriteString("</Turn>\n")
-			}
-			sb.WriteString("</History>\n")
+		// Every turn but the last has been answered; the last one is the
+		// "Current" question being answered now
+		if current := questions.CurrentTurn(); current != nil {
+			sb.WriteString(buildHistory(questions))
 
 			// B. Add the Active Interaction
-			currentQIndex := len(questions.Question) - 1
 			sb.WriteString("<CurrentInteraction>\n")
-			sb.WriteString(fmt.Sprintf("  <Question>%s</Question>\n", questions.Question[currentQIndex]))
+			sb.WriteString(fmt.Sprintf("  <Question>%s</Question>\n", current.Question))
+			if current.Code != "" {
+				sb.WriteString(fmt.Sprintf("  <Code>%s</Code>\n", current.Code))
+			}
 			sb.WriteString(fmt.Sprintf("  <CandidateAnswer>%s</CandidateAnswer>\n", answer))
 			sb.WriteString("</CurrentInteraction>\n")
 		}
 
-		// C. Add Constraints
+		// C. Add the level of the next question for every rating
+		sb.WriteString(buildNextDifficulty(models.PlanDifficulty(session, questions)))
+
+		// D. Add Constraints
 		sb.WriteString(constraintNextQuestion)
+
+	} else if session.InterviewStatus == models.Paused {
+		// --- Welcome Back Flow ---
+
+		if current := questions.CurrentTurn(); current != nil {
+			sb.WriteString(buildHistory(questions))
+
+			sb.WriteString("<CurrentQuestion>\n")
+			sb.WriteString(fmt.Sprintf("  <Question>%s</Question>\n", current.Question))
+			if current.Code != "" {
+				sb.WriteString(fmt.Sprintf("  <Code>%s</Code>\n", current.Code))
+			}
+			sb.WriteString("</CurrentQuestion>\n")
+		}
+
+		sb.WriteString(constraintWelcomeBack)
+	}
+
+	return sb.String()
+}
+
+// ReportPrompt asks for the final report of an interview from its evaluated
+// turns.
+func ReportPrompt(session *models.Session, questions *models.Question) string {
+	var sb strings.Builder
+	sb.WriteString(personaFor(session))
+	sb.WriteString(buildCandidateDetails(session))
+
+	sb.WriteString("<History>\n")
+	for _, turn := range questions.Turns {
+		if turn.Rating == nil {
+			continue
+		}
+		sb.WriteString(buildTurnHistory(&turn))
 	}
+	sb.WriteString("</History>\n")
+
+	sb.WriteString(constraintReport)
+	return sb.String()
+}
 
+// RepairPrompt re-asks the model after it returned an invalid response. The
+// original prompt is repeated with the rejected output and the reason.
+func RepairPrompt(prompt string, previousOutput string, problem error) string {
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\n<Correction>\n")
+	sb.WriteString("Your previous response was rejected and must be regenerated.\n")
+	sb.WriteString(fmt.Sprintf("Reason: %s\n", problem))
+	sb.WriteString(fmt.Sprintf("<RejectedResponse>%s</RejectedResponse>\n", previousOutput))
+	sb.WriteString("Respond again with a single JSON object that follows <StrictConstraints> exactly. ")
+	sb.WriteString("Every required field must be present and the rating must be an integer from 0 to 10.\n")
+	sb.WriteString("</Correction>\n")
 	return sb.String()
-}
\ No newline at end of file
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"github.com/rnkp755/mockinterviewBackend/models"
+	"github.com/rnkp755/mockinterviewBackend/services/llm"
+)
+
+var (
+	ratingMin = 0.0
+	ratingMax = 10.0
+)
+
+// FirstQuestionSchema is the response shape for the opening turn.
+var FirstQuestionSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"question": {Type: llm.TypeString, Description: "Greeting message and the first question"},
+		"code":     {Type: llm.TypeString, Description: "Optional code snippet for the question, empty if not needed"},
+	},
+	Required: []string{"question", "code"},
+}
+
+// NextQuestionSchema is the response shape for every follow-up turn.
+var NextQuestionSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"rating": {
+			Type:        llm.TypeInteger,
+			Description: "Rating of the candidate's answer out of 10",
+			Minimum:     &ratingMin,
+			Maximum:     &ratingMax,
+		},
+		"feedback": {
+			Type: llm.TypeObject,
+			Properties: map[string]*llm.Schema{
+				"positive":     {Type: llm.TypeString, Description: "What they did right"},
+				"negative":     {Type: llm.TypeString, Description: "What they did wrong"},
+				"improvements": {Type: llm.TypeString, Description: "How to optimize"},
+			},
+			Required: []string{"positive", "negative", "improvements"},
+		},
+		"question": {Type: llm.TypeString, Description: "The next question"},
+		"code":     {Type: llm.TypeString, Description: "Optional code snippet for the next question, empty if not needed"},
+	},
+	Required: []string{"rating", "feedback", "question", "code"},
+}
+
+// ReportSchema is the response shape for the final report.
+var ReportSchema = &llm.Schema{
+	Type: llm.TypeObject,
+	Properties: map[string]*llm.Schema{
+		"turnTopics": {
+			Type:        llm.TypeArray,
+			Description: "The topic of every evaluated question",
+			Items: &llm.Schema{
+				Type: llm.TypeObject,
+				Properties: map[string]*llm.Schema{
+					"turn":  {Type: llm.TypeInteger, Description: "Number of the question"},
+					"topic": {Type: llm.TypeString, Description: "Short topic name"},
+				},
+				Required: []string{"turn", "topic"},
+			},
+		},
+		"strengths":  {Type: llm.TypeArray, Items: &llm.Schema{Type: llm.TypeString}, Description: "What the candidate did well"},
+		"weaknesses": {Type: llm.TypeArray, Items: &llm.Schema{Type: llm.TypeString}, Description: "Where the candidate fell short"},
+		"roadmap":    {Type: llm.TypeArray, Items: &llm.Schema{Type: llm.TypeString}, Description: "Ordered steps to improve"},
+		"recommendation": {
+			Type:        llm.TypeString,
+			Description: "Overall hiring recommendation",
+			Enum:        hireRecommendations(),
+		},
+		"summary": {Type: llm.TypeString, Description: "Two or three sentence summary of the interview"},
+	},
+	Required: []string{"turnTopics", "strengths", "weaknesses", "roadmap", "recommendation", "summary"},
+}
+
+func hireRecommendations() []string {
+	values := make([]string, len(models.HireRecommendations))
+	for i, r := range models.HireRecommendations {
+		values[i] = string(r)
+	}
+	return values
+}
+
+// ResponseSchema returns the schema the interviewer must follow for a prompt
+// built from the given session state.
+func ResponseSchema(firstQuestion bool) *llm.Schema {
+	if firstQuestion {
+		return FirstQuestionSchema
+	}
+	return NextQuestionSchema
+}